@@ -0,0 +1,181 @@
+package errors
+
+import "sync/atomic"
+
+var ErrMultiple = New("multiple", "multiple errors occurred")
+
+// BatchCode returns the code and message of a batch as a whole.
+type BatchCode func(errs BatchErrors) Message
+
+// BatchCodeOf gives all batches the code and message of def.
+func BatchCodeOf(def Message) BatchCode {
+	return func(BatchErrors) Message {
+		return def
+	}
+}
+
+// DominantCode gives a batch the code and message of the first of its errors
+// having the most frequent code, or of ErrMultiple if none has a code.
+func DominantCode(errs BatchErrors) Message {
+	var counts = make(map[string]int)
+	var dominant Message
+	for _, err := range errs.Flatten() {
+		if m, ok := err.(Message); ok && m.Code() != "" {
+			counts[m.Code()]++
+			if dominant == nil || counts[m.Code()] > counts[dominant.Code()] {
+				dominant = m
+			}
+		}
+	}
+	if dominant == nil {
+		return ErrMultiple
+	}
+	return dominant
+}
+
+var batchCode atomic.Value
+
+// SetBatchCode sets how the code and message of batches are determined,
+// ErrMultiple is used by default. Batches are not bound to a registry, the
+// policy applies to all of them.
+func SetBatchCode(policy BatchCode) {
+	if policy == nil {
+		policy = BatchCodeOf(ErrMultiple)
+	}
+	batchCode.Store(policy)
+}
+
+func (e BatchErrors) message() Message {
+	if policy, ok := batchCode.Load().(BatchCode); ok {
+		if m := policy(e); m != nil {
+			return m
+		}
+	}
+	return ErrMultiple
+}
+
+func (e BatchErrors) Code() string {
+	return e.message().Code()
+}
+
+func (e BatchErrors) Message() string {
+	return e.message().Message()
+}
+
+// Is reports whether any error of the batch is target, see IsAll, or whether
+// target is the definition or category of the code of the batch, so that Is
+// agrees with Code.
+func (e BatchErrors) Is(target error) bool {
+	for _, err := range e {
+		if Is(err, target) {
+			return true
+		}
+	}
+	return e.isAggregate(target)
+}
+
+// isAggregate reports whether target is the definition or category giving
+// the batch its code. Other targets, batches included, are only matched
+// against the errors of the batch.
+func (e BatchErrors) isAggregate(target error) bool {
+	switch target.(type) {
+	case *underlying, Category:
+		if m, ok := e.message().(error); ok {
+			return Is(m, target)
+		}
+	}
+	return false
+}
+
+func (e BatchErrors) Unwrap() []error {
+	return e
+}
+
+// IsAll is like Is, but reports whether all the errors of a batch are
+// target, nested batches included.
+func IsAll(err error, target error) bool {
+	errs, ok := err.(BatchErrors)
+	if !ok {
+		return Is(err, target)
+	}
+	for _, err := range errs {
+		if !IsAll(err, target) {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
+
+// CausedByAll is like CausedBy, but reports whether all the errors of a
+// batch are caused by target, nested batches included.
+func CausedByAll(src error, target error, deepFirst bool) bool {
+	errs, ok := src.(BatchErrors)
+	if !ok {
+		return CausedBy(src, target, deepFirst)
+	}
+	for _, err := range errs {
+		if !CausedByAll(err, target, deepFirst) {
+			return false
+		}
+	}
+	return len(errs) > 0
+}
+
+// Flatten returns the errors of the batch with the errors of nested batches
+// in place of them, without nil errors.
+func (e BatchErrors) Flatten() BatchErrors {
+	var flat = make(BatchErrors, 0, len(e))
+	for _, err := range e {
+		switch s := err.(type) {
+		case nil:
+		case BatchErrors:
+			flat = append(flat, s.Flatten()...)
+		default:
+			flat = append(flat, err)
+		}
+	}
+	return flat
+}
+
+// Filter returns the errors of the flattened batch matching pred.
+func (e BatchErrors) Filter(pred func(err error) bool) BatchErrors {
+	var filtered BatchErrors
+	for _, err := range e.Flatten() {
+		if pred(err) {
+			filtered = append(filtered, err)
+		}
+	}
+	return filtered
+}
+
+// GroupByCode returns the errors of the flattened batch by code, errors
+// without code are grouped under an empty code.
+func (e BatchErrors) GroupByCode() map[string]BatchErrors {
+	var groups = make(map[string]BatchErrors)
+	for _, err := range e.Flatten() {
+		var code = errorCode(err)
+		groups[code] = append(groups[code], err)
+	}
+	return groups
+}
+
+// Codes returns the codes of the errors of the flattened batch, in order of
+// first occurrence and without duplicates.
+func (e BatchErrors) Codes() (codes []string) {
+	var seen = make(map[string]struct{})
+	for _, err := range e.Flatten() {
+		var code = errorCode(err)
+		if _, dup := seen[code]; code != "" && !dup {
+			seen[code] = struct{}{}
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+func errorCode(err error) string {
+	if m, ok := err.(Message); ok {
+		return m.Code()
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package errors_test
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var (
+	errBatchOuter = errors.New("BATCH0001", "batch failed")
+	errBatchItem  = errors.New("BATCH0002", "item failed")
+)
+
+func code(err error) string {
+	if m, ok := err.(errors.Message); ok {
+		return m.Code()
+	}
+	return ""
+}
+
+func TestBatchCause(t *testing.T) {
+	var batch = errors.Batch([]error{fmt.Errorf("a"), errors.Raise(errBatchItem, nil)})
+	if got := code(batch); got != "multiple" {
+		t.Errorf("batch code = %q, want multiple", got)
+	}
+	if !errors.Is(batch, errors.ErrMultiple) || !errors.Is(batch, errBatchItem) {
+		t.Errorf("batch is not ErrMultiple and its errors")
+	}
+
+	var err = errors.Because(errBatchOuter, batch)
+	if got := code(err); got != "BATCH0001" {
+		t.Errorf("code of an error caused by a batch = %q, want BATCH0001", got)
+	}
+	if got := err.(errors.Message).Message(); got != "batch failed" {
+		t.Errorf("message of an error caused by a batch = %q, want %q", got, "batch failed")
+	}
+	if got := code(errors.Note(err, errors.Int("n", 1))); got != "BATCH0001" {
+		t.Errorf("code of a noted error caused by a batch = %q, want BATCH0001", got)
+	}
+	if errors.Is(err, errors.ErrMultiple) {
+		t.Errorf("error caused by a batch is ErrMultiple")
+	}
+	if got := code(errors.Notef(batch, "saving")); got != "multiple" {
+		t.Errorf("code of a noted batch = %q, want multiple", got)
+	}
+}
+
+func TestBatchCodePolicy(t *testing.T) {
+	errors.SetBatchCode(errors.DominantCode)
+	defer errors.SetBatchCode(nil)
+
+	var item = errors.Raise(errBatchItem, nil)
+	var batch = errors.Batch([]error{fmt.Errorf("a"), item, item})
+	if got := code(batch); got != "BATCH0002" {
+		t.Errorf("dominant code = %q, want BATCH0002", got)
+	}
+	if errors.Is(batch, errors.ErrMultiple) {
+		t.Errorf("batch with a dominant code is ErrMultiple")
+	}
+	if !errors.IsCategory(batch, "BATCH0002") {
+		t.Errorf("batch is not in the category of its code")
+	}
+
+	var uncoded = errors.Batch([]error{fmt.Errorf("a"), fmt.Errorf("b")})
+	if got := code(uncoded); got != "multiple" || !errors.Is(uncoded, errors.ErrMultiple) {
+		t.Errorf("batch without codes has code %q, want ErrMultiple", got)
+	}
+}
+
+func TestBatchIsUnrelatedBatch(t *testing.T) {
+	var a = errors.Batch([]error{io.EOF, io.ErrClosedPipe})
+	var b = errors.Batch([]error{sql.ErrNoRows, sql.ErrTxDone})
+	if errors.Is(a, b) || errors.Is(b, a) {
+		t.Errorf("unrelated batches match each other")
+	}
+	if !errors.Is(a, io.EOF) || errors.Is(a, sql.ErrNoRows) {
+		t.Errorf("batch does not match its errors only")
+	}
+	if errors.Is(a, errBatchItem) {
+		t.Errorf("batch matches a definition which is neither its code nor one of its errors")
+	}
+}
+
+func TestBatchCausedByAggregate(t *testing.T) {
+	var batch = errors.Batch([]error{fmt.Errorf("a"), fmt.Errorf("b")})
+	for i, err := range []error{batch, errors.Note(batch)} {
+		if !errors.Is(err, errors.ErrMultiple) {
+			t.Errorf("error %d is not ErrMultiple", i)
+		}
+		if !errors.CausedBy(err, errors.ErrMultiple, false) || !errors.CausedBy(err, errors.ErrMultiple, true) {
+			t.Errorf("error %d is ErrMultiple but not caused by it", i)
+		}
+	}
+	if !errors.CausedBy(errors.Because(errBatchOuter, batch), errors.ErrMultiple, false) {
+		t.Errorf("error caused by a batch is not caused by ErrMultiple")
+	}
+	var cause error
+	if !errors.CausedByNode(batch, errors.ErrMultiple, false, &cause) || cause == nil {
+		t.Errorf("CausedByNode does not store the batch caused by ErrMultiple")
+	}
+	if errors.CausedBy(batch, errors.Batch([]error{fmt.Errorf("c"), fmt.Errorf("d")}), false) {
+		t.Errorf("batch is caused by an unrelated batch")
+	}
+}
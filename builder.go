@@ -0,0 +1,113 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+)
+
+type Builder struct {
+	reason Reason
+	cause  error
+	data   []Field
+	skip   int
+	tags   []string
+	public string
+	text   string
+	ctx    context.Context
+	reg    *Registry
+
+	classified bool
+}
+
+// Build starts building an error of reason, which may be nil to annotate a
+// cause without a definition of its own.
+func Build(reason Reason) *Builder {
+	if isNilReason(reason) {
+		reason = nil
+	}
+	return &Builder{reason: reason}
+}
+
+func (b *Builder) Cause(err error) *Builder {
+	b.cause = err
+	return b
+}
+
+func (b *Builder) With(fields ...Field) *Builder {
+	b.data = append(b.data[:len(b.data):len(b.data)], fields...)
+	return b
+}
+
+// Skip skips additional callers of Err when capturing the stack trace, so
+// that helpers building errors are not reported as their origin.
+func (b *Builder) Skip(skip int) *Builder {
+	b.skip += skip
+	return b
+}
+
+func (b *Builder) Tag(tags ...string) *Builder {
+	b.tags = append(b.tags[:len(b.tags):len(b.tags)], tags...)
+	return b
+}
+
+// Public sets a message which is safe to show to end users.
+func (b *Builder) Public(message string) *Builder {
+	b.public = message
+	return b
+}
+
+// Classified makes the built error match its cause for Is, besides its
+// reason, like the errors returned by Classify. It is meant for classifiers
+// attaching data of the foreign error they map, see sqlerr.Classify.
+func (b *Builder) Classified() *Builder {
+	b.classified = true
+	return b
+}
+
+// Ctx adds the fields extracted from ctx by the extractors registered with
+// OnNoteCtx, except the ones already given or attached to the cause.
+func (b *Builder) Ctx(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+func (b *Builder) Notef(format string, args ...interface{}) *Builder {
+	b.text = fmt.Sprintf(format, args...)
+	return b
+}
+
+// Err returns the built error, or nil if neither reason nor cause is set.
+// Fields added to an error built without reason, text, tags or public message
+// are attached to the cause when it is a traced error, without a new trace.
+func (b *Builder) Err() error {
+	if b.reason == nil && b.cause == nil {
+		return nil
+	}
+	var r = registryOf(b.reg)
+	var data = b.data
+	if b.ctx != nil {
+		data = append(data[:len(data):len(data)], r.ctxFields(b.ctx, b.cause, data)...)
+	}
+	if causeNode, ok := b.cause.(*node); ok && b.reason == nil && b.text == "" && len(b.tags) == 0 && b.public == "" {
+		if len(data) == 0 {
+			return causeNode
+		}
+		n := causeNode.clone()
+		n.data = append(n.data[:len(n.data):len(n.data)], data...)
+		r.runHooks(n, false, true)
+		return n
+	}
+	n := &node{
+		data:       data,
+		underlying: b.reason,
+		cause:      b.cause,
+		text:       b.text,
+		tags:       b.tags,
+		public:     b.public,
+		reg:        b.reg,
+		classified: b.classified,
+	}
+	n.trace(b.skip+1, r.StackDepth())
+	r.runHooks(n, true, n.cause != nil)
+	return n
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"context"
+	sysErr "errors"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+)
+
+var (
+	ErrCanceled         = New("context.canceled", "operation canceled")
+	ErrDeadlineExceeded = New("context.deadline_exceeded", "deadline exceeded")
+	ErrEOF              = New("io.eof", "end of file")
+	ErrUnexpectedEOF    = New("io.unexpected_eof", "unexpected end of file")
+	ErrNotExist         = New("fs.not_exist", "file does not exist")
+	ErrExist            = New("fs.exist", "file already exists")
+	ErrPermission       = New("fs.permission", "permission denied")
+	ErrClosed           = New("fs.closed", "file or connection already closed")
+	ErrTimeout          = New("net.timeout", "i/o timeout")
+	ErrNetwork          = New("net.error", "network error")
+)
+
+// Classifier maps foreign errors to the definitions used as their reason by
+// From and Classify.
+type Classifier func(err error) (reason Reason, ok bool)
+
+// ClassifyIs maps the errors which are target, according to the standard
+// errors.Is, to reason.
+func ClassifyIs(target error, reason Reason) Classifier {
+	return func(err error) (Reason, bool) {
+		return reason, sysErr.Is(err, target)
+	}
+}
+
+// ClassifyAs maps the errors having an error of type T in their chain,
+// according to the standard errors.As, to reason.
+func ClassifyAs[T error](reason Reason) Classifier {
+	return func(err error) (Reason, bool) {
+		var target T
+		return reason, sysErr.As(err, &target)
+	}
+}
+
+func ClassifyFunc(match func(err error) bool, reason Reason) Classifier {
+	return func(err error) (Reason, bool) {
+		return reason, match(err)
+	}
+}
+
+// builtinClassifiers map the errors of the standard library. Errno values
+// implement net.Error as well, so they are matched before it, but after the
+// network operations wrapping them.
+var builtinClassifiers = append(append([]Classifier{
+	ClassifyIs(context.Canceled, ErrCanceled),
+	ClassifyIs(context.DeadlineExceeded, ErrDeadlineExceeded),
+	ClassifyIs(os.ErrDeadlineExceeded, ErrDeadlineExceeded),
+	ClassifyIs(io.EOF, ErrEOF),
+	ClassifyIs(io.ErrUnexpectedEOF, ErrUnexpectedEOF),
+	ClassifyIs(fs.ErrNotExist, ErrNotExist),
+	ClassifyIs(fs.ErrExist, ErrExist),
+	ClassifyIs(fs.ErrPermission, ErrPermission),
+	ClassifyIs(fs.ErrClosed, ErrClosed),
+	ClassifyIs(net.ErrClosed, ErrClosed),
+	ClassifyIs(io.ErrClosedPipe, ErrClosed),
+	ClassifyFunc(isTimeout, ErrTimeout),
+	ClassifyAs[*net.OpError](ErrNetwork),
+}, errnoClassifiers...), ClassifyAs[net.Error](ErrNetwork))
+
+func isTimeout(err error) bool {
+	var t interface{ Timeout() bool }
+	return sysErr.As(err, &t) && t.Timeout()
+}
+
+// OnClassify registers classifier to be tried by From and Classify, in
+// registration order and before the built-in classifiers of standard library
+// errors. The returned function unregisters the classifier.
+func (r *Registry) OnClassify(classifier Classifier) (unregister func()) {
+	if classifier == nil {
+		return func() {}
+	}
+	return r.classifiers.add(classifier)
+}
+
+func OnClassify(classifier Classifier) (unregister func()) {
+	return defaultRegistry.OnClassify(classifier)
+}
+
+func (r *Registry) classify(err error) Reason {
+	for _, e := range r.classifiers.load() {
+		if reason, ok := e.fn.(Classifier)(err); ok && !isNilReason(reason) {
+			return reason
+		}
+	}
+	for _, classifier := range builtinClassifiers {
+		if reason, ok := classifier(err); ok {
+			return reason
+		}
+	}
+	return nil
+}
+
+// Classify returns a traced error caused by err, whose reason is the
+// definition err is mapped to by the registered classifiers, or err itself
+// if it is already traced, has a code, or is not mapped. The classified
+// error is still err for Is, e.g. Classify(io.EOF) is both ErrEOF and io.EOF.
+func (r *Registry) Classify(err error) error {
+	return r.classifyErr(err, 1)
+}
+
+func Classify(err error) error {
+	return defaultRegistry.classifyErr(err, 1)
+}
+
+// classifyErr classifies err, skipping skip callers when tracing it.
+func (r *Registry) classifyErr(err error, skip int) error {
+	switch err.(type) {
+	case nil, *node, Message, BatchErrors:
+		return err
+	}
+	if reason := r.classify(err); reason != nil {
+		return r.Build(reason).Cause(err).Classified().Skip(skip + 1).Err()
+	}
+	return err
+}
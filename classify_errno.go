@@ -0,0 +1,10 @@
+//go:build !plan9
+// +build !plan9
+
+package errors
+
+import "syscall"
+
+var ErrSyscall = New("os.syscall", "system call failed")
+
+var errnoClassifiers = []Classifier{ClassifyAs[syscall.Errno](ErrSyscall)}
@@ -0,0 +1,69 @@
+package errors_test
+
+import (
+	"context"
+	sysErrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+func TestFromEOF(t *testing.T) {
+	var err = errors.From(io.EOF)
+	if got := code(err); got != "io.eof" {
+		t.Errorf("From(io.EOF) has code %q, want io.eof", got)
+	}
+	for _, target := range []error{errors.ErrEOF, io.EOF} {
+		if !errors.Is(err, target) {
+			t.Errorf("From(io.EOF) is not %v", target)
+		}
+	}
+	if layers := errors.Layers(err); !strings.HasSuffix(layers[len(layers)-1].Frames()[0].Function, ".TestFromEOF") {
+		t.Errorf("From(io.EOF) is traced at %s, want its caller", layers[len(layers)-1].Frames()[0].Function)
+	}
+	if layers := errors.Layers(errors.Classify(io.EOF)); !strings.HasSuffix(layers[len(layers)-1].Frames()[0].Function, ".TestFromEOF") {
+		t.Errorf("Classify(io.EOF) is traced at %s, want its caller", layers[len(layers)-1].Frames()[0].Function)
+	}
+	if !sysErrors.Is(err, io.EOF) {
+		t.Errorf("From(io.EOF) is not io.EOF for the standard errors.Is")
+	}
+	if !errors.Is(errors.From(fmt.Errorf("read: %w", io.EOF)), io.EOF) {
+		t.Errorf("From of a wrapped io.EOF is not io.EOF")
+	}
+	if plain := fmt.Errorf("plain"); errors.From(plain) != plain {
+		t.Errorf("From changed an unclassified error")
+	}
+	if coded := errors.Raise(errData, io.EOF); errors.From(coded) != coded {
+		t.Errorf("From changed a traced error")
+	}
+}
+
+func TestClassifyEOF(t *testing.T) {
+	var err = errors.Classify(io.EOF)
+	for _, target := range []error{errors.ErrEOF, io.EOF} {
+		if !errors.Is(err, target) {
+			t.Errorf("Classify(io.EOF) is not %v", target)
+		}
+		if !errors.Is(errors.Note(err, errors.Int("n", 1)), target) {
+			t.Errorf("Note(Classify(io.EOF)) is not %v", target)
+		}
+		if !errors.Is(errors.Notef(err, "reading"), target) {
+			t.Errorf("Notef(Classify(io.EOF)) is not %v", target)
+		}
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Classify(io.EOF) is io.ErrUnexpectedEOF")
+	}
+	if errors.Is(errors.Because(errData, err), io.EOF) {
+		t.Errorf("an error caused by Classify(io.EOF) is io.EOF, want only CausedBy")
+	}
+	if !errors.CausedBy(errors.Because(errData, err), io.EOF, true) {
+		t.Errorf("an error caused by Classify(io.EOF) is not caused by io.EOF")
+	}
+	if got := errors.Classify(context.Canceled); !errors.Is(got, context.Canceled) || !errors.Is(got, errors.ErrCanceled) {
+		t.Errorf("Classify(context.Canceled) is not context.Canceled and ErrCanceled")
+	}
+}
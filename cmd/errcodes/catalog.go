@@ -0,0 +1,207 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const errorsPkgPath = "github.com/lipence/errors"
+
+type Definition struct {
+	Code     string            `json:"code"`
+	Message  string            `json:"message"`
+	Package  string            `json:"package"`
+	Var      string            `json:"var,omitempty"`
+	Position string            `json:"position"`
+	Key      string            `json:"key,omitempty"`
+	Assigned string            `json:"assigned,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+
+	nonConstant bool
+}
+
+type Problem struct {
+	Kind      string   `json:"kind"`
+	Code      string   `json:"code,omitempty"`
+	Positions []string `json:"positions"`
+}
+
+type Catalog struct {
+	Definitions []Definition `json:"definitions"`
+	Problems    []Problem    `json:"problems,omitempty"`
+}
+
+func loadCatalog(dir string, patterns []string, tests bool) (*Catalog, error) {
+	var cfg = &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:   dir,
+		Tests: tests,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, errLoadFailed
+	}
+	var catalog = &Catalog{}
+	var seen = make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.PkgPath == errorsPkgPath {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			for _, def := range fileDefinitions(pkg, file) {
+				// test variants of a package share the same definitions
+				if !seen[def.Position] {
+					seen[def.Position] = true
+					catalog.Definitions = append(catalog.Definitions, def)
+				}
+			}
+		}
+	}
+	sort.SliceStable(catalog.Definitions, func(i, j int) bool {
+		return catalog.Definitions[i].Package < catalog.Definitions[j].Package
+	})
+	catalog.check()
+	catalog.assignCodes()
+	return catalog, nil
+}
+
+func fileDefinitions(pkg *packages.Package, file *ast.File) (defs []Definition) {
+	var varNames = make(map[*ast.CallExpr]string)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if vs, ok := spec.(*ast.ValueSpec); ok {
+				for i, value := range vs.Values {
+					if call, ok := value.(*ast.CallExpr); ok && i < len(vs.Names) {
+						varNames[call] = vs.Names[i].Name
+					}
+				}
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isErrorsNew(pkg.TypesInfo, call) || len(call.Args) < 2 {
+			return true
+		}
+		var def = Definition{
+			Package:  pkg.PkgPath,
+			Var:      varNames[call],
+			Position: pkg.Fset.Position(call.Pos()).String(),
+		}
+		var codeOk, messageOk bool
+		def.Code, codeOk = constString(pkg.TypesInfo, call.Args[0])
+		def.Message, messageOk = constString(pkg.TypesInfo, call.Args[1])
+		def.nonConstant = !codeOk || !messageOk
+		for _, arg := range call.Args[2:] {
+			def.addOption(pkg.TypesInfo, arg)
+		}
+		defs = append(defs, def)
+		return true
+	})
+	return defs
+}
+
+// metaOptions maps the definition options setting a single metadata key to
+// this key.
+var metaOptions = map[string]string{
+	"WithOwner":    "owner",
+	"WithRunbook":  "runbook",
+	"WithDoc":      "doc",
+	"WithSeverity": "severity",
+}
+
+// addOption records the tags and metadata set by a definition option given
+// with constant arguments.
+func (d *Definition) addOption(info *types.Info, arg ast.Expr) {
+	call, ok := arg.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPkgPath {
+		return
+	}
+	var args []string
+	for _, a := range call.Args {
+		if v, ok := constString(info, a); ok {
+			args = append(args, v)
+		}
+	}
+	if len(args) != len(call.Args) || call.Ellipsis.IsValid() {
+		return
+	}
+	var setMeta = func(key, value string) {
+		if d.Meta == nil {
+			d.Meta = make(map[string]string)
+		}
+		d.Meta[key] = value
+	}
+	switch name := fn.Name(); {
+	case name == "WithTags":
+		d.Tags = append(d.Tags, args...)
+	case name == "WithMeta" && len(args) == 2:
+		setMeta(args[0], args[1])
+	case metaOptions[name] != "" && len(args) == 1:
+		setMeta(metaOptions[name], args[0])
+	}
+}
+
+func (c *Catalog) check() {
+	var positions = make(map[string][]string)
+	var keys []string
+	var codes = make(map[string]string)
+	for _, def := range c.Definitions {
+		switch {
+		case def.nonConstant:
+			c.Problems = append(c.Problems, Problem{Kind: "non-constant", Positions: []string{def.Position}})
+		case def.Code == "":
+			c.Problems = append(c.Problems, Problem{Kind: "empty", Positions: []string{def.Position}})
+		default:
+			var key = def.Code
+			// `@xxxx` masks are expanded per package in debug builds
+			if strings.HasPrefix(key, "@") {
+				key = def.Package + key
+			}
+			if len(positions[key]) == 0 {
+				keys = append(keys, key)
+				codes[key] = def.Code
+			}
+			positions[key] = append(positions[key], def.Position)
+		}
+	}
+	for _, key := range keys {
+		if len(positions[key]) > 1 {
+			c.Problems = append(c.Problems, Problem{Kind: "duplicate", Code: codes[key], Positions: positions[key]})
+		}
+	}
+}
+
+// isErrorsNew reports whether call creates a definition, with errors.New or
+// the Define method of a parent definition.
+func isErrorsNew(info *types.Info, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == errorsPkgPath && (fn.Name() == "New" || fn.Name() == "Define")
+}
+
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	var tv = info.Types[expr]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
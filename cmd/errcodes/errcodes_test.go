@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestDebugBuildCodes checks that the codes assigned by errcodes are the ones
+// assigned at runtime by debug builds, with and without -trimpath.
+func TestDebugBuildCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a module")
+	}
+	var dir = t.TempDir()
+	var app = filepath.Join("testdata", "app")
+	for _, file := range []string{"main.go", filepath.Join("sub", "sub.go")} {
+		data, err := os.ReadFile(filepath.Join(app, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, file), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var goMod = "module example.com/app\n\ngo 1.18\n\n" +
+		"require github.com/lipence/errors v0.0.0-00010101000000-000000000000\n\n" +
+		"replace github.com/lipence/errors => " + filepath.ToSlash(root) + "\n"
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := loadCatalog(dir, []string{"./..."}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var assigned = make(map[string]string)
+	for _, def := range catalog.Definitions {
+		assigned[def.Var] = def.Assigned
+	}
+	if len(assigned) != 7 {
+		t.Fatalf("got definitions %v, want 7", assigned)
+	}
+
+	for _, flags := range [][]string{{"-tags", "debug"}, {"-tags", "debug", "-trimpath"}} {
+		var cmd = exec.Command("go", append(append([]string{"run"}, flags...), ".")...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "ERRORS_CODES_LOCK=")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("go run %v: %v\n%s", flags, err, stderr.Bytes())
+		}
+		var codes map[string]string
+		if err = json.Unmarshal(out, &codes); err != nil {
+			t.Fatalf("go run %v: %v\n%s", flags, err, out)
+		}
+		for name, code := range codes {
+			if code == "" || code != assigned[name] {
+				t.Errorf("go run %v: %s has code %q, errcodes assigned %q", flags, name, code, assigned[name])
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/lipence/errors/internal/autocode"
+)
+
+// assignCodes computes the codes assigned in debug builds to definitions
+// declared with an empty code or an `@xxxx` mask, in declaration order.
+func (c *Catalog) assignCodes() {
+	var ordinals = make(map[string]int)
+	for i := range c.Definitions {
+		var def = &c.Definitions[i]
+		if def.nonConstant {
+			continue
+		}
+		if def.Code == "" {
+			var ordinal = ordinals[def.Package+":"+def.Message]
+			ordinals[def.Package+":"+def.Message]++
+			def.Key = autocode.Key(def.Package, def.Message, ordinal)
+			def.Assigned = autocode.Code(def.Package, def.Key)
+		} else if localID, ok := autocode.Mask(def.Code); ok {
+			def.Key = autocode.MaskKey(def.Package, localID)
+			def.Assigned = autocode.MaskCode(def.Package, localID)
+		}
+	}
+}
+
+// reconcileLock pins the assigned codes missing from lock, and removes the
+// entries of definitions which no longer exist. Locked codes never change,
+// so empty codes are no longer reported as problems.
+func (c *Catalog) reconcileLock(lock *autocode.Lock, report io.Writer) (changed bool) {
+	defer c.dropProblems("empty")
+	var keys = make(map[string]bool)
+	for i := range c.Definitions {
+		var def = &c.Definitions[i]
+		if def.Key == "" {
+			continue
+		}
+		keys[def.Key] = true
+		if locked, ok := lock.Codes[def.Key]; ok {
+			def.Assigned = locked
+			continue
+		}
+		fmt.Fprintf(report, "lock %s => %s\n\tat: %s\n", def.Key, def.Assigned, def.Position)
+		lock.Codes[def.Key] = def.Assigned
+		changed = true
+	}
+	var stale []string
+	for key := range lock.Codes {
+		if !keys[key] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	for _, key := range stale {
+		fmt.Fprintf(report, "unlock %s => %s (definition not found)\n", key, lock.Codes[key])
+		delete(lock.Codes, key)
+		changed = true
+	}
+	return changed
+}
+
+func (c *Catalog) dropProblems(kind string) {
+	var problems = c.Problems[:0]
+	for _, problem := range c.Problems {
+		if problem.Kind != kind {
+			problems = append(problems, problem)
+		}
+	}
+	c.Problems = problems
+}
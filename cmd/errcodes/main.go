@@ -0,0 +1,146 @@
+// Command errcodes statically extracts the error definitions created by
+// errors.New in the given packages, reports empty, non-constant and
+// duplicated codes, and prints the catalog as JSON or Markdown.
+//
+//	errcodes [-format json|markdown] [-o file] [-check] [packages]
+//
+// With -lock, the codes assigned in debug builds to definitions declared with
+// an empty code or an `@xxxx` mask are reconciled with the lock file read by
+// debug builds from $ERRORS_CODES_LOCK. -check fails if the lock file is out
+// of date, -write-lock updates it.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lipence/errors"
+	"github.com/lipence/errors/internal/autocode"
+)
+
+var errLoadFailed = errors.NewSysErr("failed to load packages")
+
+func main() {
+	var format = flag.String("format", "json", "output format, json or markdown")
+	var output = flag.String("o", "", "write the catalog to file instead of stdout")
+	var check = flag.Bool("check", false, "exit with status 1 if any problem is found")
+	var tests = flag.Bool("tests", false, "include test files")
+	var lockFile = flag.String("lock", "", "reconcile assigned codes with lock file")
+	var writeLock = flag.Bool("write-lock", false, "update the lock file given by -lock")
+	flag.Parse()
+
+	var patterns = flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	catalog, err := loadCatalog("", patterns, *tests)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "errcodes:", err)
+		os.Exit(2)
+	}
+
+	var lockChanged bool
+	if *lockFile != "" {
+		lock, err := autocode.LoadLock(*lockFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "errcodes:", err)
+			os.Exit(2)
+		}
+		if lockChanged = catalog.reconcileLock(lock, os.Stderr); lockChanged && *writeLock {
+			if err = lock.Save(*lockFile); err != nil {
+				fmt.Fprintln(os.Stderr, "errcodes:", err)
+				os.Exit(2)
+			}
+			lockChanged = false
+		}
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "json":
+		err = writeJSON(&buf, catalog)
+	case "markdown", "md":
+		err = writeMarkdown(&buf, catalog)
+	default:
+		err = errors.NewSysErrf("unknown format %q", *format)
+	}
+	if err == nil {
+		if *output != "" {
+			err = os.WriteFile(*output, buf.Bytes(), 0o644)
+		} else {
+			_, err = os.Stdout.Write(buf.Bytes())
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "errcodes:", err)
+		os.Exit(2)
+	}
+
+	for _, problem := range catalog.Problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+	if *check && (len(catalog.Problems) > 0 || lockChanged) {
+		os.Exit(1)
+	}
+}
+
+func (p Problem) String() string {
+	switch p.Kind {
+	case "duplicate":
+		return fmt.Sprintf("duplicated code %q\n\tat: %s", p.Code, strings.Join(p.Positions, "\n\tat: "))
+	case "non-constant":
+		return fmt.Sprintf("non-constant code or message\n\tat: %s", strings.Join(p.Positions, "\n\tat: "))
+	default:
+		return fmt.Sprintf("%s code\n\tat: %s", p.Kind, strings.Join(p.Positions, "\n\tat: "))
+	}
+}
+
+func writeJSON(w io.Writer, catalog *Catalog) error {
+	var encoder = json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
+
+func writeMarkdown(w io.Writer, catalog *Catalog) error {
+	var b strings.Builder
+	b.WriteString("| Code | Message | Package | Tags | Metadata | Defined at |\n")
+	b.WriteString("| ---- | ------- | ------- | ---- | -------- | ---------- |\n")
+	for _, def := range catalog.Definitions {
+		var definedAt = def.Position
+		if def.Var != "" {
+			definedAt = def.Var + " (" + def.Position + ")"
+		}
+		var meta []string
+		for key, value := range def.Meta {
+			meta = append(meta, key+": "+value)
+		}
+		sort.Strings(meta)
+		fmt.Fprintf(&b, "| `%s` | %s | `%s` | %s | %s | %s |\n",
+			def.Code, markdownEscape(def.Message), def.Package,
+			markdownEscape(strings.Join(def.Tags, ", ")), markdownEscape(strings.Join(meta, "<br>")),
+			markdownEscape(definedAt))
+	}
+	if len(catalog.Problems) > 0 {
+		b.WriteString("\n## Problems\n\n")
+		for _, problem := range catalog.Problems {
+			fmt.Fprintf(&b, "- %s", problem.Kind)
+			if problem.Code != "" {
+				fmt.Fprintf(&b, " `%s`", problem.Code)
+			}
+			fmt.Fprintf(&b, ": %s\n", markdownEscape(strings.Join(problem.Positions, ", ")))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
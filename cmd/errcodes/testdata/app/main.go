@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"example.com/app/sub"
+	"github.com/lipence/errors"
+)
+
+var (
+	ErrEmpty  = errors.New("", "empty code")
+	ErrSame   = errors.New("", "same message")
+	ErrSame2  = errors.New("", "same message")
+	ErrMasked = errors.New("@0a1f", "masked code")
+	ErrChild  = ErrEmpty.Define("", "child")
+)
+
+func main() {
+	_ = json.NewEncoder(os.Stdout).Encode(map[string]string{
+		"ErrEmpty":  ErrEmpty.Code(),
+		"ErrSame":   ErrSame.Code(),
+		"ErrSame2":  ErrSame2.Code(),
+		"ErrMasked": ErrMasked.Code(),
+		"ErrChild":  ErrChild.Code(),
+		"ErrSub":    sub.ErrSub.Code(),
+		"ErrSub2":   sub.ErrSub2.Code(),
+	})
+}
@@ -0,0 +1,7 @@
+package sub
+
+import "github.com/lipence/errors"
+
+var ErrSub = errors.New("", "same message")
+
+var ErrSub2 = errors.New("", "same message")
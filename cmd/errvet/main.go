@@ -0,0 +1,13 @@
+// Command errvet checks for misuse of github.com/lipence/errors. Run it with
+//
+//	go vet -vettool=$(which errvet) ./...
+package main
+
+import (
+	"github.com/lipence/errors/errvet"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(errvet.Analyzer)
+}
@@ -0,0 +1,24 @@
+module github.com/lipence/errors/cmd
+
+go 1.22.0
+
+require (
+	github.com/lipence/errors v0.0.0-00010101000000-000000000000
+	github.com/lipence/errors/errvet v0.0.0-00010101000000-000000000000
+	golang.org/x/tools v0.30.0
+)
+
+require (
+	go.uber.org/atomic v1.9.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	go.uber.org/zap v1.21.0 // indirect
+	golang.org/x/mod v0.23.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
+)
+
+// The commands are built against this checkout of the library and analyzer,
+// so they are installed from a clone, see the README.
+replace (
+	github.com/lipence/errors => ../
+	github.com/lipence/errors/errvet => ../errvet
+)
@@ -0,0 +1,60 @@
+package errors
+
+import "context"
+
+type CtxExtractor func(ctx context.Context) []Field
+
+// OnNoteCtx registers extractor to be applied by NoteCtx, BecauseCtx and
+// Builder.Ctx. The returned function unregisters the extractor.
+func (r *Registry) OnNoteCtx(extractor CtxExtractor) (unregister func()) {
+	if extractor == nil {
+		return func() {}
+	}
+	return r.extractors.add(extractor)
+}
+
+func OnNoteCtx(extractor CtxExtractor) (unregister func()) {
+	return defaultRegistry.OnNoteCtx(extractor)
+}
+
+func CtxValue(key string, ctxKey interface{}) CtxExtractor {
+	return func(ctx context.Context) []Field {
+		if v := ctx.Value(ctxKey); v != nil {
+			return []Field{Any(key, v)}
+		}
+		return nil
+	}
+}
+
+func NoteCtx(ctx context.Context, err error, fields ...Field) error {
+	if err == nil {
+		return nil
+	}
+	return Build(nil).Cause(err).With(fields...).Ctx(ctx).Skip(1).Err()
+}
+
+func BecauseCtx(ctx context.Context, reason Reason, cause error, fields ...Field) error {
+	if cause == nil {
+		return nil
+	}
+	return Build(reason).Cause(cause).With(fields...).Ctx(ctx).Skip(1).Err()
+}
+
+// ctxFields extracts fields from ctx, skipping keys which are already given
+// explicitly or attached at an inner layer of cause.
+func (r *Registry) ctxFields(ctx context.Context, cause error, fields []Field) (extracted []Field) {
+	var seen = make(map[string]struct{}, len(fields))
+	for i := 0; i < len(fields); i++ {
+		seen[fields[i].Key] = struct{}{}
+	}
+	for _, e := range r.extractors.load() {
+		for _, field := range e.fn.(CtxExtractor)(ctx) {
+			if _, ok := seen[field.Key]; ok || HasData(cause, field.Key, true) {
+				continue
+			}
+			seen[field.Key] = struct{}{}
+			extracted = append(extracted, field)
+		}
+	}
+	return extracted
+}
@@ -0,0 +1,61 @@
+package errors_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+type requestIDKey struct{}
+
+var errCtx = errors.New("CTX0001", "request failed")
+
+func TestNoteCtx(t *testing.T) {
+	defer errors.OnNoteCtx(errors.CtxValue("request_id", requestIDKey{}))()
+	var ctx = context.WithValue(context.Background(), requestIDKey{}, "r1")
+
+	var err = errors.NoteCtx(ctx, fmt.Errorf("x"))
+	if got, _ := errors.DataString(err, "request_id"); got != "r1" {
+		t.Errorf("request_id = %q, want r1", got)
+	}
+	err = errors.NoteCtx(ctx, fmt.Errorf("x"), errors.String("request_id", "given"))
+	if got := errors.AllData(err, errors.KeepAll, false).Values["request_id"]; !reflect.DeepEqual(got, []interface{}{"given"}) {
+		t.Errorf("request_id = %v, want the given field only", got)
+	}
+	if err = errors.NoteCtx(context.Background(), fmt.Errorf("x")); errors.HasData(err, "request_id", true) {
+		t.Errorf("request_id extracted from a context without it")
+	}
+	if err = errors.NoteCtx(ctx, nil); err != nil {
+		t.Errorf("NoteCtx(nil) = %v, want nil", err)
+	}
+}
+
+func TestBecauseCtx(t *testing.T) {
+	defer errors.OnNoteCtx(errors.CtxValue("request_id", requestIDKey{}))()
+	var ctx = context.WithValue(context.Background(), requestIDKey{}, "r1")
+
+	var inner = errors.BecauseCtx(ctx, errCtx, fmt.Errorf("x"))
+	if !errors.Is(inner, errCtx) {
+		t.Errorf("BecauseCtx error is not its reason: %v", inner)
+	}
+	var tests = []struct {
+		name string
+		err  error
+	}{
+		{"note", errors.NoteCtx(ctx, inner)},
+		{"because", errors.BecauseCtx(ctx, errCtx, inner)},
+		{"note of a note", errors.NoteCtx(ctx, errors.Notef(inner, "retrying"))},
+		{"other context", errors.BecauseCtx(context.WithValue(ctx, requestIDKey{}, "r2"), errCtx, inner)},
+	}
+	for _, test := range tests {
+		if got := errors.AllData(test.err, errors.KeepAll, false).Values["request_id"]; !reflect.DeepEqual(got, []interface{}{"r1"}) {
+			t.Errorf("%s: request_id = %v, want it once from the inner layer", test.name, got)
+		}
+	}
+	if err := errors.BecauseCtx(ctx, errCtx, nil); err != nil {
+		t.Errorf("BecauseCtx without cause = %v, want nil", err)
+	}
+}
@@ -0,0 +1,278 @@
+package errors
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	ErrDataNotFound = NewSysErr("data field not found")
+	ErrDataType     = NewSysErr("data field type mismatch")
+)
+
+// lookupField searches the data fields of src from the innermost layer to the
+// outermost one, the same order as Data(key, true). The field is returned
+// with the redaction policy of its layer applied: a masked field is a string
+// field holding the masked value.
+func lookupField(src error, key string) (Field, bool) {
+	srcNode, ok := src.(*node)
+	if !ok {
+		return Field{}, false
+	}
+	if field, found := lookupField(srcNode.cause, key); found {
+		return field, true
+	}
+	for i := 0; i < len(srcNode.data); i++ {
+		if srcNode.data[i].Key == key {
+			return srcNode.registry().redact(srcNode.data[i : i+1])[0], true
+		}
+	}
+	return Field{}, false
+}
+
+func typedField(src error, key string, want string, types ...zapcore.FieldType) (Field, error) {
+	field, found := lookupField(src, key)
+	if !found {
+		return field, NewSysErrf("%w: %q", ErrDataNotFound, key)
+	}
+	for _, t := range types {
+		if field.Type == t {
+			return field, nil
+		}
+	}
+	return field, NewSysErrf("%w: %q is %s, not %s", ErrDataType, key, fieldTypeName(field), want)
+}
+
+func DataString(src error, key string) (string, error) {
+	field, err := typedField(src, key, "string",
+		zapcore.StringType, zapcore.ByteStringType, zapcore.StringerType)
+	if err != nil {
+		return "", err
+	}
+	return rawValue(field).(string), nil
+}
+
+func DataBool(src error, key string) (bool, error) {
+	field, err := typedField(src, key, "bool", zapcore.BoolType)
+	if err != nil {
+		return false, err
+	}
+	return field.Integer == 1, nil
+}
+
+func DataInt64(src error, key string) (int64, error) {
+	field, err := typedField(src, key, "int64",
+		zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type)
+	if err != nil {
+		return 0, err
+	}
+	return field.Integer, nil
+}
+
+func DataUint64(src error, key string) (uint64, error) {
+	field, err := typedField(src, key, "uint64",
+		zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(field.Integer), nil
+}
+
+func DataFloat64(src error, key string) (float64, error) {
+	field, err := typedField(src, key, "float64", zapcore.Float64Type, zapcore.Float32Type)
+	if err != nil {
+		return 0, err
+	}
+	if field.Type == zapcore.Float32Type {
+		return float64(rawValue(field).(float32)), nil
+	}
+	return rawValue(field).(float64), nil
+}
+
+func DataDuration(src error, key string) (time.Duration, error) {
+	field, err := typedField(src, key, "time.Duration", zapcore.DurationType)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(field.Integer), nil
+}
+
+func DataTime(src error, key string) (time.Time, error) {
+	field, err := typedField(src, key, "time.Time", zapcore.TimeType, zapcore.TimeFullType)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return rawValue(field).(time.Time), nil
+}
+
+// DataAs returns the data field as T, which must be the exact type stored by
+// the field constructor, e.g. int64 for Int, string for String, and the
+// original value type for Reflect. Like the other typed accessors, it returns
+// the field of the innermost layer when key is attached at several layers.
+// Masked fields are only returned as string, use Reveal to read secrets.
+func DataAs[T any](src error, key string) (T, error) {
+	var zero T
+	field, found := lookupField(src, key)
+	if !found {
+		return zero, NewSysErrf("%w: %q", ErrDataNotFound, key)
+	}
+	if v, ok := rawValue(field).(T); ok {
+		return v, nil
+	}
+	return zero, NewSysErrf("%w: %q is %s, not %T", ErrDataType, key, fieldTypeName(field), zero)
+}
+
+// rawValue returns the value stored by a field without encoding it, using
+// the type the field was constructed with.
+func rawValue(field Field) interface{} {
+	switch field.Type {
+	case zapcore.BoolType:
+		return field.Integer == 1
+	case zapcore.Int64Type:
+		return field.Integer
+	case zapcore.Int32Type:
+		return int32(field.Integer)
+	case zapcore.Int16Type:
+		return int16(field.Integer)
+	case zapcore.Int8Type:
+		return int8(field.Integer)
+	case zapcore.Uint64Type:
+		return uint64(field.Integer)
+	case zapcore.Uint32Type:
+		return uint32(field.Integer)
+	case zapcore.Uint16Type:
+		return uint16(field.Integer)
+	case zapcore.Uint8Type:
+		return uint8(field.Integer)
+	case zapcore.UintptrType:
+		return uintptr(field.Integer)
+	case zapcore.Float64Type:
+		return math.Float64frombits(uint64(field.Integer))
+	case zapcore.Float32Type:
+		return math.Float32frombits(uint32(field.Integer))
+	case zapcore.DurationType:
+		return time.Duration(field.Integer)
+	case zapcore.StringType:
+		return field.String
+	case zapcore.ByteStringType:
+		return string(field.Interface.([]byte))
+	case zapcore.StringerType:
+		return field.Interface.(fmt.Stringer).String()
+	case zapcore.TimeType:
+		if field.Interface != nil {
+			return time.Unix(0, field.Integer).In(field.Interface.(*time.Location))
+		}
+		return time.Unix(0, field.Integer)
+	case zapcore.TimeFullType, zapcore.Complex128Type, zapcore.Complex64Type,
+		zapcore.BinaryType, zapcore.ErrorType, zapcore.ReflectType,
+		zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType:
+		return field.Interface
+	}
+	return nil
+}
+
+func fieldTypeName(field Field) string {
+	switch field.Type {
+	case zapcore.ArrayMarshalerType, zapcore.ObjectMarshalerType, zapcore.ReflectType, zapcore.ErrorType:
+		return fmt.Sprintf("%T", field.Interface)
+	}
+	if v := rawValue(field); v != nil {
+		return fmt.Sprintf("%T", v)
+	}
+	return fmt.Sprintf("zapcore.FieldType(%d)", field.Type)
+}
+
+type DataPrecedence int
+
+const (
+	InnermostWins DataPrecedence = iota
+	OutermostWins
+	KeepAll
+)
+
+type DataConflict struct {
+	Key    string
+	Values []interface{}
+}
+
+type MergedData struct {
+	Keys      []string
+	Values    map[string]interface{}
+	Conflicts []DataConflict
+}
+
+// AllData merges the data fields of every layer of src, and of every element
+// of the BatchErrors in its chain, into a single view. The elements of a batch
+// are merged as layers inner to the one of the batch. Keys are ordered by their
+// first appearance from the innermost layer. With KeepAll, each value is a
+// []interface{} holding the values of all layers. When namespaced is set,
+// keys are prefixed with the code of their layer, or its index if it has no
+// code.
+func AllData(src error, precedence DataPrecedence, namespaced bool) *MergedData {
+	var merged = &MergedData{Values: make(map[string]interface{})}
+	var all = make(map[string][]interface{})
+	var index int
+	for _, layer := range allLayers(src) {
+		var prefix string
+		if namespaced {
+			if prefix = layer.Code(); prefix == "" {
+				prefix = strconv.Itoa(index)
+			}
+			prefix += "."
+		}
+		index++
+		for _, field := range layer.Data {
+			var key, value = prefix + field.Key, fieldValue(field)
+			if _, seen := all[key]; !seen {
+				merged.Keys = append(merged.Keys, key)
+			}
+			all[key] = append(all[key], value)
+		}
+	}
+	for _, key := range merged.Keys {
+		var values = all[key]
+		switch precedence {
+		case KeepAll:
+			merged.Values[key] = values
+		case OutermostWins:
+			merged.Values[key] = values[len(values)-1]
+		default:
+			merged.Values[key] = values[0]
+		}
+		for i := 1; i < len(values); i++ {
+			if !reflect.DeepEqual(values[0], values[i]) {
+				merged.Conflicts = append(merged.Conflicts, DataConflict{Key: key, Values: values})
+				break
+			}
+		}
+	}
+	return merged
+}
+
+func allLayers(src error) []Layer {
+	switch s := src.(type) {
+	case nil:
+		return nil
+	case jsonErr:
+		return allLayers(s.error)
+	case BatchErrors:
+		var layers []Layer
+		for _, err := range s {
+			layers = append(layers, allLayers(err)...)
+		}
+		return layers
+	}
+	var layers []Layer
+	for _, layer := range Layers(src) {
+		if errs, ok := layer.Err.(BatchErrors); ok {
+			layers = append(layers, allLayers(errs)...)
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
@@ -0,0 +1,111 @@
+package errors_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var (
+	errData      = errors.New("DATA0001", "data")
+	errDataOuter = errors.New("DATA0002", "outer data")
+)
+
+func TestTypedDataRedacted(t *testing.T) {
+	var r = errors.NewRegistry()
+	r.SetRedactPolicy(&errors.RedactPolicy{Rules: []errors.RedactRule{
+		errors.KeyRule("token", errors.MaskFull),
+		errors.KeyRule("pin", errors.MaskFull),
+	}})
+	var err = r.Build(errData).Cause(fmt.Errorf("c")).With(
+		errors.String("token", "s3cr3t"),
+		errors.Int64("pin", 1234),
+		errors.Secret("password", "hunter2"),
+		errors.Int64("attempt", 2),
+	).Err()
+
+	if got, _ := errors.DataString(err, "token"); got != "******" {
+		t.Errorf("DataString(token) = %q, want masked", got)
+	}
+	if got, _ := errors.DataAs[string](err, "token"); got != "******" {
+		t.Errorf("DataAs[string](token) = %q, want masked", got)
+	}
+	if got, _ := errors.DataString(err, "password"); got != "******" {
+		t.Errorf("DataString(password) = %q, want masked", got)
+	}
+	if got, dataErr := errors.DataInt64(err, "pin"); got != 0 || !errors.Is(dataErr, errors.ErrDataType) {
+		t.Errorf("DataInt64(pin) = %d, %v, want a type error", got, dataErr)
+	}
+	if got, _ := errors.DataAs[int64](err, "pin"); got != 0 {
+		t.Errorf("DataAs[int64](pin) = %d, want masked", got)
+	}
+	if got, _ := errors.DataAs[int64](err, "attempt"); got != 2 {
+		t.Errorf("DataAs[int64](attempt) = %d, want 2", got)
+	}
+	if got, _ := errors.Reveal(err, "password", true); got != "hunter2" {
+		t.Errorf("Reveal(password) = %v, want hunter2", got)
+	}
+}
+
+func TestAllDataBatch(t *testing.T) {
+	var batch = errors.Batch([]error{
+		errors.Because(errData, fmt.Errorf("x"), errors.Int("a", 1)),
+		errors.Because(errData, fmt.Errorf("y"), errors.Int("b", 2)),
+	})
+	var tests = []struct {
+		name string
+		err  error
+		keys []string
+	}{
+		{"batch", batch, []string{"a", "b"}},
+		{"noted batch", errors.Note(batch, errors.Int("c", 3)), []string{"a", "b", "c"}},
+		{"error caused by a batch", errors.Because(errDataOuter, batch, errors.Int("c", 3)), []string{"a", "b", "c"}},
+		{"nested batch", errors.Batch([]error{errors.Note(batch, errors.Int("c", 3)), errors.Raise(errData, nil, errors.Int("d", 4))}), []string{"a", "b", "c", "d"}},
+	}
+	for _, test := range tests {
+		var merged = errors.AllData(test.err, errors.InnermostWins, false)
+		if !reflect.DeepEqual(merged.Keys, test.keys) {
+			t.Errorf("%s: keys = %v, want %v", test.name, merged.Keys, test.keys)
+		}
+	}
+	var merged = errors.AllData(errors.Because(errDataOuter, batch, errors.Int("c", 3)), errors.InnermostWins, true)
+	var keys = []string{"DATA0001.a", "DATA0001.b", "DATA0002.c"}
+	if !reflect.DeepEqual(merged.Keys, keys) {
+		t.Errorf("namespaced keys = %v, want %v", merged.Keys, keys)
+	}
+}
+
+func TestAllDataPrecedence(t *testing.T) {
+	var inner = errors.Because(errData, fmt.Errorf("x"), errors.String("k", "inner"), errors.String("same", "v"))
+	var err = errors.Because(errDataOuter, inner, errors.String("k", "outer"), errors.String("same", "v"))
+	var tests = []struct {
+		precedence errors.DataPrecedence
+		want       interface{}
+	}{
+		{errors.InnermostWins, "inner"},
+		{errors.OutermostWins, "outer"},
+		{errors.KeepAll, []interface{}{"inner", "outer"}},
+	}
+	for _, test := range tests {
+		var merged = errors.AllData(err, test.precedence, false)
+		if got := merged.Values["k"]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("precedence %d: k = %v, want %v", test.precedence, got, test.want)
+		}
+		var conflicts = []errors.DataConflict{{Key: "k", Values: []interface{}{"inner", "outer"}}}
+		if !reflect.DeepEqual(merged.Conflicts, conflicts) {
+			t.Errorf("precedence %d: conflicts = %v, want %v", test.precedence, merged.Conflicts, conflicts)
+		}
+	}
+
+	var noted = errors.Build(nil).Cause(inner).Notef("retrying").With(errors.String("k", "note")).Err()
+	var merged = errors.AllData(noted, errors.InnermostWins, true)
+	var keys = []string{"DATA0001.k", "DATA0001.same", "2.k"}
+	if !reflect.DeepEqual(merged.Keys, keys) {
+		t.Errorf("namespaced keys = %v, want %v", merged.Keys, keys)
+	}
+	if len(merged.Conflicts) != 0 {
+		t.Errorf("namespaced keys conflict: %v", merged.Conflicts)
+	}
+}
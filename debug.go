@@ -4,67 +4,56 @@
 package errors
 
 import (
-	"bytes"
 	"fmt"
-	"hash/crc32"
 	sysLog "log"
 	"os"
-	"path/filepath"
-	"regexp"
 	"runtime"
-	"runtime/debug"
-	"strconv"
 	"sync"
 
-	"go.uber.org/atomic"
+	"github.com/lipence/errors/internal/autocode"
 )
 
-type err_packetASCType = struct {
-	packageId uint32
-	codeIdASC uint32
-}
+const err_lockFileEnv = "ERRORS_CODES_LOCK"
 
-var err_packetASCCount = atomic.Uint32{}
-var err_packetASCMap = sync.Map{} // used to store package asc id & code asc id
-var err_codeFileMap = sync.Map{}  // used to avoid redeclared code
-var err_mainPackagePath []byte    // used to trim errors.New caller package path
-var err_exprInternalCodeMask = regexp.MustCompile("^@([0-9a-f]{4})$")
+var err_codeFileMap = sync.Map{}     // used to avoid redeclared code
+var err_messageOrdinals = sync.Map{} // used to distinguish declarations with the same message
+var err_lock *autocode.Lock
 
 func init() {
-	if info, ok := debug.ReadBuildInfo(); ok {
-		err_mainPackagePath = append([]byte(filepath.Clean(info.Main.Path)), os.PathSeparator)
+	if path := os.Getenv(err_lockFileEnv); path != "" {
+		var err error
+		if err_lock, err = autocode.LoadLock(path); err != nil {
+			panic(err)
+		}
 	}
 }
 
 func errMessageFilter(code, msg string) (newCode, newMessage string) {
-	_, file, line, ok := runtime.Caller(2)
+	pc, file, line, ok := runtime.Caller(3)
 	if !ok {
 		panic("failed to get caller package")
 	}
-	var packageASC *err_packetASCType
-	var packageCRC = crc32.ChecksumIEEE(bytes.TrimPrefix([]byte(filepath.Dir(file)), err_mainPackagePath))
-	{
-		if _packetASCItf, ok := err_packetASCMap.Load(packageCRC); ok {
-			packageASC = _packetASCItf.(*err_packetASCType)
-		}
-		if packageASC == nil {
-			packageASC = &err_packetASCType{err_packetASCCount.Add(1), 0}
-		}
-		defer err_packetASCMap.Store(packageCRC, packageASC)
+	var pkgPath string
+	if fn := runtime.FuncForPC(pc); fn != nil {
+		pkgPath = funcPackage(fn.Name())
+	}
+	var key string
+	if code == "" {
+		ordinalItf, _ := err_messageOrdinals.LoadOrStore(pkgPath+":"+msg, new(int))
+		var ordinal = *ordinalItf.(*int)
+		*ordinalItf.(*int) += 1
+		key = autocode.Key(pkgPath, msg, ordinal)
+		code = autocode.Code(pkgPath, key)
+		defer sysLog.Printf("empty error code at %s:%d , temporarily use code `%s` for message `%s`", file, line, code, msg)
+	} else if localID, isMask := autocode.Mask(code); isMask {
+		key = autocode.MaskKey(pkgPath, localID)
+		code = autocode.MaskCode(pkgPath, localID)
 	}
-	{
-		if code == "" {
-			packageASC.codeIdASC += 1
-			code = fmt.Sprintf("%08x%04x%04x", packageCRC, packageASC.packageId, packageASC.codeIdASC)
-			sysLog.Printf("empty error code at %s:%d , temporarily use code `%s` for message `%s`", file, line, code, msg)
-		} else if match := err_exprInternalCodeMask.FindAllStringSubmatch(code, -1); len(match) > 0 {
-			var codeLocalId = match[0][1]
-			if codeLocalIdUint, err := strconv.ParseUint(codeLocalId, 16, 32); err != nil {
-				panic(fmt.Errorf("invalid local code id: %w", err))
-			} else if uint64(packageASC.codeIdASC) < codeLocalIdUint {
-				packageASC.codeIdASC = uint32(codeLocalIdUint)
-			}
-			code = fmt.Sprintf("%08x%04x%04s", packageCRC, packageASC.packageId, codeLocalId)
+	if key != "" && err_lock != nil {
+		if locked, ok := err_lock.Codes[key]; ok {
+			code = locked
+		} else {
+			sysLog.Printf("error code `%s` of `%s` at %s:%d is not locked", code, key, file, line)
 		}
 	}
 	if anotherFile, ok := err_codeFileMap.Load(code); ok {
@@ -0,0 +1,144 @@
+package errors
+
+import (
+	"fmt"
+	sysLog "log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type alias struct {
+	def   *underlying
+	until time.Time
+}
+
+// aliasesMu serializes the declarations of aliases, which are stored on both
+// definitions so that each one is the other when matched with Is. Readers load
+// the list of a definition without locking, as it is replaced on each update.
+var aliasesMu sync.Mutex
+
+func addAlias(a, b *underlying, until time.Time) {
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	a.storeAlias(alias{b, until})
+	b.storeAlias(alias{a, until})
+}
+
+func (e *underlying) storeAlias(a alias) {
+	var list = e.aliasList()
+	e.aliases.Store(append(list[:len(list):len(list)], a))
+}
+
+func (e *underlying) aliasList() []alias {
+	list, _ := e.aliases.Load().([]alias)
+	return list
+}
+
+func (e *underlying) aliasOf(target *underlying) bool {
+	for _, a := range e.aliasList() {
+		if a.def.equal(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Aliases returns the codes of the aliases of e whose transition period is
+// not over.
+func (e *underlying) Aliases() (codes []string) {
+	var now = time.Now()
+	for _, a := range e.aliasList() {
+		if a.until.IsZero() || now.Before(a.until) {
+			codes = append(codes, a.def.code)
+		}
+	}
+	return codes
+}
+
+// AliasOf declares the definition as an alias of def: errors of either one
+// are the other one as well.
+func AliasOf(def *underlying) DefOption {
+	return func(alias *underlying) {
+		if def != nil {
+			addAlias(alias, def, time.Time{})
+		}
+	}
+}
+
+// Deprecated declares the definition as deprecated, and replacement, if not
+// nil, as its alias until the end of the transition period, after which the
+// code of the other one is no longer rendered in JSON. A zero until never
+// ends the transition period.
+func Deprecated(replacement *underlying, until time.Time) DefOption {
+	return func(def *underlying) {
+		def.deprecation = &deprecation{replacement: replacement}
+		if replacement != nil {
+			addAlias(def, replacement, until)
+		}
+	}
+}
+
+type deprecation struct {
+	replacement *underlying
+	warned      int32
+}
+
+func (e *underlying) Deprecated() (replacement *underlying, deprecated bool) {
+	if e.deprecation == nil {
+		return nil, false
+	}
+	return e.deprecation.replacement, true
+}
+
+// Deprecation describes the first error raised with a deprecated definition.
+type Deprecation struct {
+	Definition  Reason
+	Replacement Reason
+	Stack       []uintptr
+}
+
+type DeprecationHook func(d Deprecation)
+
+// OnDeprecated registers hook to be called once per deprecated definition,
+// the first time an error is raised with it. Without any hook, a warning is
+// logged instead. The returned function unregisters the hook.
+func (r *Registry) OnDeprecated(hook DeprecationHook) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return r.deprecationHooks.add(hook)
+}
+
+func OnDeprecated(hook DeprecationHook) (unregister func()) {
+	return defaultRegistry.OnDeprecated(hook)
+}
+
+func (r *Registry) warnDeprecated(n *node) {
+	def, ok := n.underlying.(*underlying)
+	if !ok || def.deprecation == nil || !atomic.CompareAndSwapInt32(&def.deprecation.warned, 0, 1) {
+		return
+	}
+	var d = Deprecation{Definition: def, Stack: append([]uintptr(nil), n.tracer.stack...)}
+	if def.deprecation.replacement != nil {
+		d.Replacement = def.deprecation.replacement
+	}
+	var hooks = r.deprecationHooks.load()
+	for _, e := range hooks {
+		e.fn.(DeprecationHook)(d)
+	}
+	if len(hooks) > 0 {
+		return
+	}
+	var position string
+	if len(d.Stack) > 0 {
+		frame, _ := runtime.CallersFrames(d.Stack[:1]).Next()
+		position = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+	}
+	if d.Replacement != nil {
+		sysLog.Printf("deprecated error code `%s` raised at %s, use `%s` instead", def.code, position, d.Replacement.Code())
+	} else {
+		sysLog.Printf("deprecated error code `%s` raised at %s", def.code, position)
+	}
+}
@@ -0,0 +1,106 @@
+package errors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lipence/errors"
+)
+
+var (
+	errDeprNew     = errors.New("DEPR0002", "new")
+	errDeprOld     = errors.New("DEPR0001", "old", errors.Deprecated(errDeprNew, time.Now().Add(time.Hour)))
+	errDeprExpired = errors.New("DEPR0003", "expired", errors.Deprecated(errDeprNew, time.Now().Add(-time.Hour)))
+	errDeprAlias   = errors.New("DEPR0004", "alias", errors.AliasOf(errDeprNew))
+	errDeprHooked  = errors.New("DEPR0005", "hooked", errors.Deprecated(nil, time.Time{}))
+	errDeprLogged  = errors.New("DEPR0006", "logged", errors.Deprecated(errDeprNew, time.Time{}))
+)
+
+// captureLog returns the standard logger output written by fn.
+func captureLog(fn func()) string {
+	var buf bytes.Buffer
+	var out = log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(out)
+	fn()
+	return buf.String()
+}
+
+func TestDeprecatedIs(t *testing.T) {
+	captureLog(func() {
+		for _, def := range []errors.Reason{errDeprOld, errDeprExpired, errDeprAlias} {
+			if !errors.Is(errors.Raise(def, nil), errDeprNew) {
+				t.Errorf("%s is not its replacement", def.Code())
+			}
+			if !errors.Is(errors.Raise(errDeprNew, nil), def) {
+				t.Errorf("replacement is not %s", def.Code())
+			}
+		}
+		if errors.Is(errors.Raise(errDeprOld, nil), errDeprAlias) {
+			t.Errorf("aliases of the same definition are each other")
+		}
+	})
+	if replacement, ok := errDeprOld.Deprecated(); !ok || replacement != errDeprNew {
+		t.Errorf("Deprecated() = %v, %v, want the replacement", replacement, ok)
+	}
+	if _, ok := errDeprAlias.Deprecated(); ok {
+		t.Errorf("alias is deprecated")
+	}
+}
+
+func TestDeprecatedWarnedOnce(t *testing.T) {
+	var r = errors.NewRegistry()
+	var warnings []errors.Deprecation
+	r.OnDeprecated(func(d errors.Deprecation) { warnings = append(warnings, d) })
+	for i := 0; i < 3; i++ {
+		_ = r.Build(errDeprHooked).Err()
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("got %d deprecation warnings, want 1", len(warnings))
+	}
+	if warnings[0].Definition != errDeprHooked || warnings[0].Replacement != nil || len(warnings[0].Stack) == 0 {
+		t.Errorf("warning = %+v, want the definition and its stack without replacement", warnings[0])
+	}
+
+	var logged = captureLog(func() {
+		for i := 0; i < 3; i++ {
+			_ = errors.Raise(errDeprLogged, nil)
+		}
+	})
+	if n := strings.Count(logged, "deprecated error code"); n != 1 {
+		t.Errorf("got %d logged warnings, want 1:\n%s", n, logged)
+	}
+	if !strings.Contains(logged, "`DEPR0006`") || !strings.Contains(logged, "use `DEPR0002` instead") {
+		t.Errorf("logged warning does not name the codes:\n%s", logged)
+	}
+}
+
+func TestDeprecatedAliasesJSON(t *testing.T) {
+	var tests = []struct {
+		def     errors.Reason
+		aliases string
+	}{
+		{errDeprOld, `"aliases":["DEPR0002"]`},
+		{errDeprExpired, ""},
+	}
+	for _, test := range tests {
+		var data []byte
+		var err error
+		captureLog(func() { data, err = json.Marshal(errors.Raise(test.def, nil)) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.aliases == "" && strings.Contains(string(data), `"aliases"`) {
+			t.Errorf("%s: aliases rendered after the transition period: %s", test.def.Code(), data)
+		} else if test.aliases != "" && !strings.Contains(string(data), test.aliases) {
+			t.Errorf("%s: %s not rendered: %s", test.def.Code(), test.aliases, data)
+		}
+	}
+	if got := errDeprNew.Aliases(); strings.Join(got, ",") != "DEPR0001,DEPR0004,DEPR0006" {
+		t.Errorf("replacement aliases = %v, want the ones whose transition period is not over", got)
+	}
+}
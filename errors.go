@@ -23,36 +23,44 @@ func NewSysErrf(message string, args ...interface{}) error {
 
 type MsgFilter func(code, msg string) (newCode, newMessage string)
 
-var msgFilters []MsgFilter
+func OnCreateMsg(filter MsgFilter) (unregister func()) {
+	return defaultRegistry.OnCreateMsg(filter)
+}
 
-func OnCreateMsg(filter MsgFilter) {
-	if filter != nil {
-		msgFilters = append(msgFilters, filter)
+func New(code, msg string, opts ...DefOption) *underlying {
+	code, msg = defaultRegistry.filter(code, msg)
+	var def = &underlying{code: code, message: msg}
+	for _, opt := range opts {
+		opt(def)
 	}
+	registerDefinition(def, 1)
+	return def
 }
 
-func New(code, msg string) *underlying {
-	for i := 0; i < len(msgFilters); i++ {
-		if filter := msgFilters[i]; filter != nil {
-			code, msg = filter(code, msg)
-		}
+func Because(reason Reason, cause error, fields ...Field) error {
+	if cause == nil {
+		return nil
 	}
-	return &underlying{code: code, message: msg}
+	return Build(reason).Cause(cause).With(fields...).Skip(1).Err()
 }
 
-func Because(underlying *underlying, cause error, fields ...Field) error {
+// BecauseSkip is like Because, but skips skip additional callers when
+// capturing the stack trace.
+func BecauseSkip(skip int, reason Reason, cause error, fields ...Field) error {
 	if cause == nil {
 		return nil
 	}
-	n := &node{
-		data:       fields,
-		underlying: underlying,
-		cause:      cause,
-	}
-	n.trace(1)
-	return n
+	return Build(reason).Cause(cause).With(fields...).Skip(skip + 1).Err()
+}
+
+// Raise is like Because, but returns a traced error of reason even if cause
+// is nil.
+func Raise(reason Reason, cause error, fields ...Field) error {
+	return Build(reason).Cause(cause).With(fields...).Skip(1).Err()
 }
 
+// From converts src to an error, tracing runtime errors and the errors mapped
+// to a definition by the classifiers, see Classify.
 func From(src interface{}) error {
 	switch s := src.(type) {
 	case nil:
@@ -62,42 +70,48 @@ func From(src interface{}) error {
 	case *underlying:
 		return s
 	case runtime.Error:
-		n := &node{cause: s}
-		n.trace(1)
-		return n
+		return Build(nil).Cause(s).Skip(1).Err()
 	case error:
-		return s
+		return defaultRegistry.classifyErr(s, 1)
 	default:
 		return NewSysErrf("%v", src)
 	}
 }
 
 func Note(err error, fields ...Field) error {
+	return Build(nil).Cause(err).With(fields...).Skip(1).Err()
+}
+
+// NoteSkip is like Note, but skips skip additional callers when capturing
+// the stack trace.
+func NoteSkip(skip int, err error, fields ...Field) error {
+	return Build(nil).Cause(err).With(fields...).Skip(skip + 1).Err()
+}
+
+func Notef(err error, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	if n, ok := err.(*node); ok {
-		if len(fields) > 0 {
-			n = n.clone()
-			n.data = append(n.data, fields...)
-		}
-		return n
-	}
-	n := &node{data: fields, cause: err}
-	n.trace(1)
-	return n
+	return Build(nil).Cause(err).Notef(format, args...).Skip(1).Err()
 }
 
 func Is(err error, target error) bool {
 	if err == nil || target == nil {
 		return err == target
 	}
+	if c, ok := target.(Category); ok {
+		return c.match(err)
+	}
 	if c, ok := err.(ComparableErr); ok {
 		return c.Is(target)
 	}
 	return sysErr.Is(err, target)
 }
 
+func IsCategory(err error, category string) bool {
+	return Is(err, Category(category))
+}
+
 func Data(src error, key string, r bool) (interface{}, bool) {
 	if srcNode, ok := src.(*node); ok {
 		return srcNode.Data(key, r)
@@ -112,12 +126,39 @@ func HasData(src error, key string, r bool) bool {
 	return false
 }
 
+// Public returns the outermost public message of src.
+func Public(src error) string {
+	for n, ok := src.(*node); ok; n, ok = n.cause.(*node) {
+		if n.public != "" {
+			return n.public
+		}
+	}
+	return ""
+}
+
 func CausedBy(src error, target error, deepFirst bool) bool {
 	return CausedByNode(src, target, deepFirst, nil)
 }
 
+// CausedByNode is like CausedBy, and stores the outermost layer caused by
+// target in causeReceiver. A batch is caused by target if any of its errors
+// is, see CausedByAll, or if target is the definition of its code as for Is.
 func CausedByNode(src error, target error, deepFirst bool, causeReceiver *error) (isCausedBy bool) {
 	var cause error
+	if errs, ok := src.(BatchErrors); ok {
+		for _, err := range errs {
+			if CausedByNode(err, target, deepFirst, causeReceiver) {
+				return true
+			}
+		}
+		if errs.isAggregate(target) {
+			if causeReceiver != nil {
+				*causeReceiver = src
+			}
+			return true
+		}
+		return false
+	}
 	if n, ok := src.(*node); ok {
 		isCausedBy, cause = n.CausedBy(target, deepFirst)
 		if causeReceiver != nil {
@@ -149,14 +190,18 @@ func (e BatchErrors) Error() string {
 }
 
 func (e BatchErrors) MarshalJSON() ([]byte, error) {
-	var errArr = e
-	for i, err := range errArr {
+	var errArr = make([]error, len(e))
+	for i, err := range e {
 		errArr[i] = AsJsonMarshaller(err)
 	}
 	return json.Marshal(errArr)
 }
 
 func Batch(errs []error) error {
+	return batch(defaultRegistry, errs)
+}
+
+func batch(r *Registry, errs []error) error {
 	var _errs = make([]error, 0, len(errs))
 	for _, _err := range errs {
 		if _err != nil {
@@ -169,6 +214,7 @@ func Batch(errs []error) error {
 	case 1:
 		return _errs[0]
 	default:
+		r.runBatchHooks(_errs)
 		return BatchErrors(_errs)
 	}
 }
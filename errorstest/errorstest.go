@@ -0,0 +1,85 @@
+package errorstest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+func AssertCode(t testing.TB, err error, code string) bool {
+	t.Helper()
+	m, ok := err.(errors.Message)
+	if !ok {
+		t.Errorf("error %v has no code, want %q", err, code)
+		return false
+	}
+	if got := m.Code(); got != code {
+		t.Errorf("error code is %q, want %q\n%v", got, code, err)
+		return false
+	}
+	return true
+}
+
+func AssertIs(t testing.TB, err error, target error) bool {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("error is not %v\n%v", target, err)
+		return false
+	}
+	return true
+}
+
+func AssertCausedBy(t testing.TB, err error, target error) bool {
+	t.Helper()
+	if !errors.CausedBy(err, target, false) {
+		t.Errorf("error is not caused by %v\n%v", target, err)
+		return false
+	}
+	return true
+}
+
+// AssertData checks the data field key of any layer of err, comparing values
+// by their JSON encoding, so that want may be given as int for Int fields.
+func AssertData(t testing.TB, err error, key string, want interface{}) bool {
+	t.Helper()
+	got, found := errors.Data(err, key, true)
+	if !found {
+		t.Errorf("error has no data field %q\n%v", key, err)
+		return false
+	}
+	if reflect.DeepEqual(got, want) {
+		return true
+	}
+	gotJSON, gotErr := json.Marshal(got)
+	wantJSON, wantErr := json.Marshal(want)
+	if gotErr != nil || wantErr != nil || string(gotJSON) != string(wantJSON) {
+		t.Errorf("data field %q is %#v, want %#v\n%v", key, got, want, err)
+		return false
+	}
+	return true
+}
+
+// AssertChain checks the codes of the layers of err, from the innermost layer
+// to the outermost one, ignoring layers without code.
+func AssertChain(t testing.TB, err error, codes ...string) bool {
+	t.Helper()
+	var got []string
+	for _, layer := range errors.Layers(err) {
+		if code := layer.Code(); code != "" {
+			got = append(got, code)
+		}
+	}
+	if len(got) != len(codes) {
+		t.Errorf("error chain is %q, want %q\n%v", got, codes, err)
+		return false
+	}
+	for i := range got {
+		if got[i] != codes[i] {
+			t.Errorf("error chain is %q, want %q\n%v", got, codes, err)
+			return false
+		}
+	}
+	return true
+}
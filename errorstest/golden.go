@@ -0,0 +1,214 @@
+package errorstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var update = flag.Bool("errorstest.update", false, "update golden files of errorstest.AssertGolden")
+
+var (
+	exprFrameFunc = regexp.MustCompile(`^\[\d+\] (.*)$`)
+	exprFrameLine = regexp.MustCompile(`^(.*):\d+$`)
+)
+
+// Text renders err like Error, with frame indices and line numbers replaced by
+// `#`, file paths shortened to their parent directory and base name, and
+// frames of the runtime and testing packages removed.
+func Text(err error) string {
+	if err == nil {
+		return ""
+	}
+	return normalizeText(err.Error())
+}
+
+func normalizeText(text string) string {
+	var lines = strings.Split(text, "\n")
+	var normalized = make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		var fn = strings.TrimLeft(lines[i], "\x20")
+		var indent = lines[i][:len(lines[i])-len(fn)]
+		var match = exprFrameFunc.FindStringSubmatch(fn)
+		if indent == "" || match == nil || i+1 >= len(lines) ||
+			!strings.HasPrefix(lines[i+1], indent+"\x20\x20") {
+			normalized = append(normalized, lines[i])
+			continue
+		}
+		i++
+		if isIgnoredFunc(match[1]) {
+			continue
+		}
+		normalized = append(normalized,
+			indent+normalizeFunc(match[1]),
+			indent+"\x20\x20"+normalizeLine(strings.TrimLeft(lines[i], "\x20")))
+	}
+	return strings.Join(normalized, "\n")
+}
+
+// JSON renders err like MarshalJSON, indented and normalized like Text.
+// Errors which do not implement json.Marshaler are rendered as their message,
+// like the elements of a BatchErrors. Stack traces embedded in messages, as
+// in the elements of a BatchErrors, are normalized like Text. An error is
+// returned when the output has an unexpected shape.
+func JSON(err error) (string, error) {
+	var marshaler, ok = err.(json.Marshaler)
+	if !ok {
+		marshaler = errors.AsJsonMarshaller(err).(json.Marshaler)
+	}
+	data, marshalErr := marshaler.MarshalJSON()
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	var decoder = json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if decodeErr := decoder.Decode(&value); decodeErr != nil {
+		return "", decodeErr
+	}
+	if value, marshalErr = normalizeJSON(value); marshalErr != nil {
+		return "", marshalErr
+	}
+	if data, marshalErr = json.Marshal(value); marshalErr != nil {
+		return "", marshalErr
+	}
+	var buf bytes.Buffer
+	if indentErr := json.Indent(&buf, data, "", "  "); indentErr != nil {
+		return "", indentErr
+	}
+	return buf.String(), nil
+}
+
+// normalizeJSON normalizes the JSON rendering of an error: null, a message,
+// an array of layers, or an array of such renderings for a BatchErrors.
+func normalizeJSON(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return normalizeText(v), nil
+	case []interface{}:
+		for i, item := range v {
+			var err error
+			if layer, ok := item.(map[string]interface{}); ok {
+				err = normalizeLayer(layer)
+			} else {
+				v[i], err = normalizeJSON(item)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected error JSON of type %T: %v", value, value)
+}
+
+func normalizeLayer(layer map[string]interface{}) error {
+	for key, value := range layer {
+		switch key {
+		case "data", "meta", "tags", "aliases":
+		case "stackTrace":
+			stackTrace, err := normalizeStackTrace(value)
+			if err != nil {
+				return err
+			}
+			if len(stackTrace) > 0 {
+				layer[key] = stackTrace
+			} else {
+				delete(layer, key)
+			}
+		case "underlying", "note":
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("unexpected %s of type %T in layer", key, value)
+			}
+			layer[key] = normalizeText(text)
+		default:
+			return fmt.Errorf("unexpected key %q in layer", key)
+		}
+	}
+	return nil
+}
+
+func normalizeStackTrace(value interface{}) ([]interface{}, error) {
+	stackTrace, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected stackTrace of type %T", value)
+	}
+	var normalized = make([]interface{}, 0, len(stackTrace))
+	for _, frameItf := range stackTrace {
+		frame, ok := frameItf.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected stack frame of type %T", frameItf)
+		}
+		fn, fnOk := frame["func"].(string)
+		line, lineOk := frame["line"].(string)
+		if !fnOk || !lineOk {
+			return nil, fmt.Errorf("unexpected stack frame %v", frame)
+		}
+		if match := exprFrameFunc.FindStringSubmatch(fn); match != nil {
+			if isIgnoredFunc(match[1]) {
+				continue
+			}
+			frame["func"] = normalizeFunc(match[1])
+		}
+		frame["line"] = normalizeLine(line)
+		normalized = append(normalized, frame)
+	}
+	return normalized, nil
+}
+
+// AssertGolden compares got with the content of the golden file, or writes
+// got to it when the test binary runs with -errorstest.update.
+func AssertGolden(t testing.TB, got string, goldenFile string) bool {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(goldenFile, []byte(got), 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return true
+	}
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run with -errorstest.update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match golden file %s\n--- got:\n%s\n--- want:\n%s", goldenFile, got, want)
+		return false
+	}
+	return true
+}
+
+func isIgnoredFunc(fn string) bool {
+	return strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "testing.")
+}
+
+func normalizeFunc(fn string) string {
+	return "[#] " + fn
+}
+
+func normalizeLine(line string) string {
+	if match := exprFrameLine.FindStringSubmatch(line); match != nil {
+		line = match[1]
+	}
+	var file = filepath.ToSlash(line)
+	if dir := path.Base(path.Dir(file)); dir != "." && dir != "/" {
+		file = dir + "/" + path.Base(file)
+	} else {
+		file = path.Base(file)
+	}
+	return file + ":#"
+}
@@ -0,0 +1,59 @@
+package errorstest_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+	"github.com/lipence/errors/errorstest"
+)
+
+var errGolden = errors.New("GOLDEN0001", "golden")
+
+func TestJSON(t *testing.T) {
+	var err = errors.Because(errGolden, fmt.Errorf("cause"), errors.Int("n", 1))
+	var tests = []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{"node", err, []string{`"underlying": "GOLDEN0001: golden"`, `"n": 1`, `"func": "[#] github.com/lipence/errors/errorstest_test.TestJSON"`, `"line": "errorstest/golden_test.go:#"`}},
+		{"batch", errors.Batch([]error{err, fmt.Errorf("plain"), errors.Batch([]error{err})}), []string{`"plain"`, `[#] github.com/lipence/errors/errorstest_test.TestJSON\n    errorstest/golden_test.go:#`}},
+		{"definition", errGolden, []string{`"GOLDEN0001: golden"`}},
+		{"plain", fmt.Errorf("plain"), []string{`"plain"`}},
+	}
+	for _, test := range tests {
+		got, jsonErr := errorstest.JSON(test.err)
+		if jsonErr != nil {
+			t.Errorf("%s: %v", test.name, jsonErr)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: JSON does not contain %s\n%s", test.name, want, got)
+			}
+		}
+		if strings.Contains(got, "runtime.") || strings.Contains(got, "/lipence/errors/errorstest/") {
+			t.Errorf("%s: JSON is not normalized\n%s", test.name, got)
+		}
+	}
+}
+
+type shapeErr string
+
+func (e shapeErr) Error() string {
+	return "shape"
+}
+
+func (e shapeErr) MarshalJSON() ([]byte, error) {
+	return []byte(e), nil
+}
+
+func TestJSONUnexpectedShape(t *testing.T) {
+	for _, data := range []string{`{"message":"x"}`, `[1]`, `[{"stackTrace":"x"}]`, `[{"unknown":1}]`} {
+		if got, err := errorstest.JSON(shapeErr(data)); err == nil {
+			t.Errorf("JSON of %s succeeded:\n%s", data, got)
+		}
+	}
+}
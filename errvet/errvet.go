@@ -0,0 +1,206 @@
+// Package errvet defines an analyzer checking for misuse of
+// github.com/lipence/errors: definitions created inside functions or with a
+// non-constant code, discarded errors, comparisons with == and duplicated
+// codes.
+//
+// Duplicated codes are detected within a package and against the packages it
+// imports, directly or not: the analyzer never sees two sibling packages
+// together, nor packages of other binaries. Run
+// `errcodes -check ./...` to check the codes of a whole module.
+package errvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const errorsPkgPath = "github.com/lipence/errors"
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "errvet",
+	Doc:       "check for misuse of github.com/lipence/errors definitions and constructors",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(codesFact)},
+}
+
+// codesFact records the codes defined by a package, so that duplicates can be
+// detected in the packages importing it.
+type codesFact struct {
+	Codes map[string]string // code => position
+}
+
+func (*codesFact) AFact() {}
+
+func (f *codesFact) String() string {
+	var codes = make([]string, 0, len(f.Codes))
+	for code := range f.Codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return "codes(" + strings.Join(codes, ", ") + ")"
+}
+
+// constructors return a new error on each call.
+var constructors = map[string]bool{
+	"Because":     true,
+	"BecauseCtx":  true,
+	"BecauseSkip": true,
+	"Classify":    true,
+	"Raise":       true,
+	"Note":        true,
+	"NoteCtx":     true,
+	"NoteSkip":    true,
+	"Notef":       true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if pass.Pkg.Path() == errorsPkgPath {
+		return nil, nil
+	}
+	var defined = make(map[string]string)
+	var imported = make(map[string]string)
+	for _, fact := range pass.AllPackageFacts() {
+		if codes, ok := fact.Fact.(*codesFact); ok {
+			for code, pos := range codes.Codes {
+				imported[code] = pos
+			}
+		}
+	}
+
+	var ins = pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	var nodeFilter = []ast.Node{(*ast.CallExpr)(nil), (*ast.ExprStmt)(nil), (*ast.BinaryExpr)(nil)}
+	ins.WithStack(nodeFilter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			if errorsFunc(pass, n) == "New" || errorsMethod(pass, n) == "Define" {
+				checkNew(pass, n, stack, defined, imported)
+			}
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok {
+				if name := errorsFunc(pass, call); constructors[name] {
+					pass.Reportf(call.Pos(), "result of errors.%s is discarded", name)
+				}
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.EQL || n.Op == token.NEQ {
+				checkComparison(pass, n)
+			}
+		}
+		return true
+	})
+
+	if len(defined) > 0 {
+		pass.ExportPackageFact(&codesFact{Codes: defined})
+	}
+	return nil, nil
+}
+
+func checkNew(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node, defined, imported map[string]string) {
+e1:
+	for _, ancestor := range stack {
+		switch ancestor.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			pass.Reportf(call.Pos(), "errors.New should define a package-level variable, not be called inside a function")
+			break e1
+		}
+	}
+	if len(call.Args) == 0 {
+		return
+	}
+	var tv = pass.TypesInfo.Types[call.Args[0]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		pass.Reportf(call.Args[0].Pos(), "error code passed to errors.New should be a constant string")
+		return
+	}
+	var code = constant.StringVal(tv.Value)
+	if code == "" {
+		return
+	}
+	var pos = pass.Fset.Position(call.Pos()).String()
+	if another, ok := defined[code]; ok {
+		pass.Reportf(call.Args[0].Pos(), "duplicated error code %q, also defined at %s", code, another)
+		return
+	}
+	// `@xxxx` masks are expanded per package in debug builds
+	if another, ok := imported[code]; ok && !strings.HasPrefix(code, "@") {
+		pass.Reportf(call.Args[0].Pos(), "duplicated error code %q, also defined at %s", code, another)
+	}
+	defined[code] = pos
+}
+
+func checkComparison(pass *analysis.Pass, expr *ast.BinaryExpr) {
+	for _, operand := range []ast.Expr{expr.X, expr.Y} {
+		if call, ok := astutil.Unparen(operand).(*ast.CallExpr); ok {
+			if name := errorsFunc(pass, call); constructors[name] {
+				pass.Reportf(expr.Pos(), "result of errors.%s is a new error which never equals another one, use errors.Is or errors.CausedBy", name)
+				return
+			}
+		}
+	}
+	if isNil(pass, expr.X) || isNil(pass, expr.Y) {
+		return
+	}
+	for _, operand := range []ast.Expr{expr.X, expr.Y} {
+		if isDefinition(pass.TypesInfo.TypeOf(operand)) {
+			pass.Reportf(expr.Pos(), "comparing errors with a definition using %s does not match traced errors, use errors.Is", expr.Op)
+			return
+		}
+	}
+}
+
+// errorsFunc returns the name of the function of the errors package called
+// by call, or an empty string.
+func errorsFunc(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPkgPath {
+		return ""
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+// errorsMethod returns the name of the method of a type of the errors package
+// called by call, or an empty string.
+func errorsMethod(pass *analysis.Pass, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != errorsPkgPath {
+		return ""
+	}
+	if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() == nil {
+		return ""
+	}
+	return fn.Name()
+}
+
+func isDefinition(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	var obj = named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == errorsPkgPath && obj.Name() == "underlying"
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
@@ -0,0 +1,12 @@
+package errvet_test
+
+import (
+	"testing"
+
+	"github.com/lipence/errors/errvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), errvet.Analyzer, "dep", "a")
+}
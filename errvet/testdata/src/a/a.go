@@ -0,0 +1,46 @@
+package a // want package:"codes\\(@0001, A0001, A0002, A0004, DEP0001\\)"
+
+import (
+	"dep"
+
+	"github.com/lipence/errors"
+)
+
+const codeA = "A0001"
+
+var (
+	ErrA    = errors.New(codeA, "a")
+	ErrDupA = errors.New("A0001", "a again") // want `duplicated error code "A0001", also defined at .*a.go:12:12`
+	ErrDep  = errors.New("DEP0001", "dep")   // want `duplicated error code "DEP0001", also defined at .*dep.go:5:14`
+	ErrMask = errors.New("@0001", "masked")
+	ErrSub  = ErrA.Define("A0002", "sub")
+	ErrSub2 = ErrA.Define("A0002", "sub again") // want `duplicated error code "A0002"`
+)
+
+var code = "A0003"
+
+var ErrVar = errors.New(code, "variable") // want `error code passed to errors.New should be a constant string`
+
+func inside() error {
+	var err = errors.New("A0004", "inside") // want `errors.New should define a package-level variable, not be called inside a function`
+	return err
+}
+
+func discard(err error) {
+	errors.Because(ErrA, err) // want `result of errors.Because is discarded`
+	errors.Note(err)          // want `result of errors.Note is discarded`
+	_ = errors.Raise(ErrA, err)
+}
+
+func compare(err error) bool {
+	if err == errors.Raise(ErrA, nil) { // want `result of errors.Raise is a new error which never equals another one`
+		return true
+	}
+	if err == ErrA { // want `comparing errors with a definition using == does not match traced errors`
+		return true
+	}
+	if ErrA != nil && err != nil {
+		return errors.Is(err, dep.ErrDep)
+	}
+	return false
+}
@@ -0,0 +1,5 @@
+package dep // want package:"codes\\(DEP0001\\)"
+
+import "github.com/lipence/errors"
+
+var ErrDep = errors.New("DEP0001", "dependency")
@@ -0,0 +1,43 @@
+// Package errors is a stub of github.com/lipence/errors for the errvet tests.
+package errors
+
+type underlying struct {
+	code, message string
+}
+
+func (e *underlying) Error() string {
+	return e.code + ": " + e.message
+}
+
+func (e *underlying) Define(code, msg string) *underlying {
+	return &underlying{code: code, message: msg}
+}
+
+type node struct {
+	reason *underlying
+	cause  error
+}
+
+func (e *node) Error() string {
+	return e.reason.Error()
+}
+
+func New(code, msg string) *underlying {
+	return &underlying{code: code, message: msg}
+}
+
+func Because(reason *underlying, cause error) error {
+	return &node{reason: reason, cause: cause}
+}
+
+func Raise(reason *underlying, cause error) error {
+	return &node{reason: reason, cause: cause}
+}
+
+func Note(err error) error {
+	return err
+}
+
+func Is(err, target error) bool {
+	return err == target
+}
@@ -0,0 +1,8 @@
+package errors
+
+// DefinitionCount returns the number of recorded definitions.
+func DefinitionCount() int {
+	definitions.Lock()
+	defer definitions.Unlock()
+	return len(definitions.list)
+}
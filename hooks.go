@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// View is a read-only view of a traced error, passed to the hooks registered
+// with OnTrace and OnWrap.
+type View struct {
+	n *node
+}
+
+// Err returns the error itself, which must not be modified.
+func (v View) Err() error {
+	return v.n
+}
+
+func (v View) Reason() Reason {
+	return v.n.underlying
+}
+
+func (v View) Cause() error {
+	return v.n.cause
+}
+
+func (v View) Note() string {
+	return v.n.text
+}
+
+func (v View) Public() string {
+	return v.n.public
+}
+
+func (v View) Tags() []string {
+	return append([]string(nil), v.n.tags...)
+}
+
+// Data returns the fields of the layer, redacted.
+func (v View) Data() []Field {
+	return v.n.registry().redact(v.n.data)
+}
+
+func (v View) Stack() []uintptr {
+	return append([]uintptr(nil), v.n.tracer.stack...)
+}
+
+type Hook func(v View)
+
+type BatchHook func(errs []error)
+
+// hookList is a copy-on-write list of hooks, called in registration order.
+type hookList struct {
+	mu   sync.Mutex
+	list atomic.Value // []*hookEntry
+}
+
+type hookEntry struct {
+	fn interface{}
+}
+
+func (h *hookList) add(fn interface{}) (unregister func()) {
+	var entry = &hookEntry{fn: fn}
+	h.mu.Lock()
+	var list = h.load()
+	h.list.Store(append(list[:len(list):len(list)], entry))
+	h.mu.Unlock()
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		var list = h.load()
+		for i, e := range list {
+			if e == entry {
+				var newList = make([]*hookEntry, 0, len(list)-1)
+				newList = append(newList, list[:i]...)
+				h.list.Store(append(newList, list[i+1:]...))
+				return
+			}
+		}
+	}
+}
+
+func (h *hookList) load() []*hookEntry {
+	var list, _ = h.list.Load().([]*hookEntry)
+	return list
+}
+
+// copyTo registers the hooks of h in dst, which must not be in use yet.
+func (h *hookList) copyTo(dst *hookList) {
+	var list = h.load()
+	dst.list.Store(append([]*hookEntry(nil), list...))
+}
+
+// OnTrace registers hook to be called whenever a stack trace is captured for
+// a new error, by Because, Note, From, Recover and the other constructors.
+// The returned function unregisters the hook.
+func (r *Registry) OnTrace(hook Hook) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return r.traceHooks.add(hook)
+}
+
+// OnWrap registers hook to be called whenever an error wraps a cause, with or
+// without capturing a new stack trace, e.g. when fields are noted on a traced
+// error. The returned function unregisters the hook.
+func (r *Registry) OnWrap(hook Hook) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return r.wrapHooks.add(hook)
+}
+
+// OnBatch registers hook to be called with a copy of the errors whenever
+// Batch combines several errors. The returned function unregisters the hook.
+func (r *Registry) OnBatch(hook BatchHook) (unregister func()) {
+	if hook == nil {
+		return func() {}
+	}
+	return r.batchHooks.add(hook)
+}
+
+func OnTrace(hook Hook) (unregister func()) {
+	return defaultRegistry.OnTrace(hook)
+}
+
+func OnWrap(hook Hook) (unregister func()) {
+	return defaultRegistry.OnWrap(hook)
+}
+
+func OnBatch(hook BatchHook) (unregister func()) {
+	return defaultRegistry.OnBatch(hook)
+}
+
+func (r *Registry) runHooks(n *node, traced, wrapped bool) {
+	if traced {
+		for _, e := range r.traceHooks.load() {
+			e.fn.(Hook)(View{n})
+		}
+		r.warnDeprecated(n)
+	}
+	if wrapped {
+		for _, e := range r.wrapHooks.load() {
+			e.fn.(Hook)(View{n})
+		}
+	}
+}
+
+func (r *Registry) runBatchHooks(errs BatchErrors) {
+	for _, e := range r.batchHooks.load() {
+		e.fn.(BatchHook)(append([]error(nil), errs...))
+	}
+}
@@ -0,0 +1,79 @@
+// Package autocode derives the codes assigned to error definitions declared
+// with an empty code or an `@xxxx` mask in debug builds. Codes only depend on
+// the package path, the message and the order of the declaration, which the
+// errcodes tool reads from the source and debug builds from the binary, so
+// that they are the same across builds, -trimpath ones included, and may be
+// pinned in a lock file once released.
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+var exprMask = regexp.MustCompile("^@([0-9a-f]{4})$")
+
+// Mask returns the local id of an `@xxxx` code mask.
+func Mask(code string) (localID string, ok bool) {
+	if match := exprMask.FindStringSubmatch(code); match != nil {
+		return match[1], true
+	}
+	return "", false
+}
+
+// Key identifies a declaration with an empty code by its message and the
+// number of preceding declarations with an empty code and the same message
+// in the package.
+func Key(pkgPath, message string, ordinal int) string {
+	if ordinal > 0 {
+		return pkgPath + ":" + message + "#" + strconv.Itoa(ordinal)
+	}
+	return pkgPath + ":" + message
+}
+
+func MaskKey(pkgPath, localID string) string {
+	return pkgPath + "@" + localID
+}
+
+func Code(pkgPath, key string) string {
+	return fmt.Sprintf("%08x%08x", crc32.ChecksumIEEE([]byte(pkgPath)), crc32.ChecksumIEEE([]byte(key)))
+}
+
+func MaskCode(pkgPath, localID string) string {
+	return fmt.Sprintf("%08x%08s", crc32.ChecksumIEEE([]byte(pkgPath)), localID)
+}
+
+type Lock struct {
+	Codes map[string]string `json:"codes"` // key => code
+}
+
+// LoadLock reads the lock file at path, returning an empty lock if it does
+// not exist.
+func LoadLock(path string) (*Lock, error) {
+	var lock = &Lock{Codes: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid lock file %s: %w", path, err)
+	}
+	if lock.Codes == nil {
+		lock.Codes = make(map[string]string)
+	}
+	return lock, nil
+}
+
+func (l *Lock) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,73 @@
+package errors
+
+import "runtime"
+
+type Layer struct {
+	Err   error
+	Note  string
+	Data  []Field
+	Stack []uintptr
+}
+
+func (l Layer) Code() string {
+	if m, ok := l.Err.(Message); ok {
+		return m.Code()
+	}
+	return ""
+}
+
+func (l Layer) Message() string {
+	if m, ok := l.Err.(Message); ok {
+		return m.Message()
+	}
+	if l.Err != nil {
+		return l.Err.Error()
+	}
+	return l.Note
+}
+
+func (l Layer) Frames() []runtime.Frame {
+	if len(l.Stack) == 0 {
+		return nil
+	}
+	var frames = make([]runtime.Frame, 0, len(l.Stack))
+	var iter = runtime.CallersFrames(l.Stack)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// Layers returns the layers of err from the innermost cause to the outermost
+// wrapper, in the same order as they are rendered by Error and MarshalJSON.
+func Layers(err error) []Layer {
+	if err == nil {
+		return nil
+	}
+	if n, ok := err.(*node); ok {
+		return n.layers()
+	}
+	return []Layer{{Err: err}}
+}
+
+func (e *node) layers() []Layer {
+	var layers []Layer
+	var layer = Layer{Note: e.text, Data: e.registry().redact(e.data), Stack: e.stack}
+	if e.cause != nil {
+		if causeNode, ok := e.cause.(*node); ok {
+			layers = causeNode.layers()
+		} else if e.underlying != nil || e.text != "" {
+			layers = append(layers, Layer{Err: e.cause})
+		} else {
+			layer.Err = e.cause
+		}
+	}
+	if e.underlying != nil {
+		layer.Err = e.underlying
+	}
+	return append(layers, layer)
+}
@@ -0,0 +1,156 @@
+package errors
+
+const (
+	MetaOwner    = "owner"
+	MetaRunbook  = "runbook"
+	MetaDoc      = "doc"
+	MetaSeverity = "severity"
+)
+
+type DefOption func(def *underlying)
+
+func WithTags(tags ...string) DefOption {
+	return func(def *underlying) {
+		def.tags = append(def.tags, tags...)
+	}
+}
+
+func WithMeta(key, value string) DefOption {
+	return func(def *underlying) {
+		if def.meta == nil {
+			def.meta = make(map[string]string)
+		}
+		def.meta[key] = value
+	}
+}
+
+func WithOwner(team string) DefOption {
+	return WithMeta(MetaOwner, team)
+}
+
+func WithRunbook(url string) DefOption {
+	return WithMeta(MetaRunbook, url)
+}
+
+func WithDoc(url string) DefOption {
+	return WithMeta(MetaDoc, url)
+}
+
+func WithSeverity(severity string) DefOption {
+	return WithMeta(MetaSeverity, severity)
+}
+
+// Tags returns the tags of e and its parent definitions.
+func (e *underlying) Tags() (tags []string) {
+	for d := e; d != nil; d = d.parent {
+		tags = append(tags, d.tags...)
+	}
+	return tags
+}
+
+// Meta returns the metadata key of e, or of its nearest parent definition
+// having it.
+func (e *underlying) Meta(key string) (string, bool) {
+	for d := e; d != nil; d = d.parent {
+		if value, ok := d.meta[key]; ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// MetaMap returns the metadata of e merged with the one of its parents.
+func (e *underlying) MetaMap() map[string]string {
+	var meta map[string]string
+	for d := e; d != nil; d = d.parent {
+		for key, value := range d.meta {
+			if meta == nil {
+				meta = make(map[string]string)
+			}
+			if _, ok := meta[key]; !ok {
+				meta[key] = value
+			}
+		}
+	}
+	return meta
+}
+
+// walkLayers calls fn with the occurrence tags and the definition of every
+// layer of src from the outermost one, including the elements of batches,
+// until fn returns true.
+func walkLayers(src error, fn func(tags []string, def *underlying) bool) bool {
+	switch s := src.(type) {
+	case *node:
+		def, _ := s.underlying.(*underlying)
+		if fn(s.tags, def) {
+			return true
+		}
+		return walkLayers(s.cause, fn)
+	case *underlying:
+		return fn(nil, s)
+	case jsonErr:
+		return walkLayers(s.error, fn)
+	case BatchErrors:
+		for _, err := range s {
+			if walkLayers(err, fn) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Tags returns the tags of every layer of src, given when raising the error
+// or when defining its definitions, from the outermost layer to the innermost
+// one, without duplicates.
+func Tags(src error) (tags []string) {
+	var seen = make(map[string]struct{})
+	var add = func(tag string) {
+		if _, dup := seen[tag]; !dup {
+			seen[tag] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+	walkLayers(src, func(occurrenceTags []string, def *underlying) bool {
+		for _, tag := range occurrenceTags {
+			add(tag)
+		}
+		for _, tag := range def.Tags() {
+			add(tag)
+		}
+		return false
+	})
+	return tags
+}
+
+func HasTag(src error, tag string) bool {
+	return walkLayers(src, func(occurrenceTags []string, def *underlying) bool {
+		for _, t := range occurrenceTags {
+			if t == tag {
+				return true
+			}
+		}
+		for _, t := range def.Tags() {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Meta returns the metadata key of the outermost definition of src having it.
+func Meta(src error, key string) (value string, found bool) {
+	walkLayers(src, func(_ []string, def *underlying) bool {
+		if def != nil {
+			value, found = def.Meta(key)
+		}
+		return found
+	})
+	return value, found
+}
+
+// RenderMeta enables the output of definition tags and metadata in JSON.
+func RenderMeta(enabled bool) {
+	defaultRegistry.RenderMeta(enabled)
+}
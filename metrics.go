@@ -0,0 +1,223 @@
+package errors
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	metricsOther           = "other"
+	metricsDefaultSeverity = "error"
+)
+
+// MetricsOptions configures the occurrence metrics. Namespace prefixes the
+// metric names and defaults to `errors`. MaxSeries bounds the number of label
+// combinations and defaults to 1000, occurrences of new combinations beyond it
+// are counted with all labels set to `other`.
+type MetricsOptions struct {
+	Namespace string
+	MaxSeries int
+}
+
+type MetricLabels struct {
+	Code      string `json:"code"`
+	OuterCode string `json:"outerCode"`
+	Package   string `json:"package"`
+	Severity  string `json:"severity"`
+}
+
+type MetricSeries struct {
+	MetricLabels
+	Count     uint64    `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Metrics counts the traced errors created by Because, Note, From and the
+// other constructors once enabled by EnableMetrics, or registered as a trace
+// hook of a Registry. Codes which were not defined with New are counted as
+// `other`.
+type Metrics struct {
+	opts   MetricsOptions
+	mu     sync.Mutex
+	series map[MetricLabels]*MetricSeries
+}
+
+func NewMetrics(opts MetricsOptions) *Metrics {
+	if opts.Namespace == "" {
+		opts.Namespace = "errors"
+	}
+	if opts.MaxSeries <= 0 {
+		opts.MaxSeries = 1000
+	}
+	return &Metrics{opts: opts, series: make(map[MetricLabels]*MetricSeries)}
+}
+
+var metrics atomic.Value
+
+func init() {
+	metrics.Store((*Metrics)(nil))
+	OnTrace(observeMetrics)
+}
+
+// EnableMetrics starts counting traced errors in m, a nil m stops counting.
+func EnableMetrics(m *Metrics) {
+	metrics.Store(m)
+}
+
+func observeMetrics(v View) {
+	if m := metrics.Load().(*Metrics); m != nil {
+		m.Observe(v)
+	}
+}
+
+// Observe counts v, it can be registered with Registry.OnTrace.
+func (m *Metrics) Observe(v View) {
+	var labels = metricLabels(v.n)
+	var now = time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[labels]
+	if !ok {
+		if len(m.series) >= m.opts.MaxSeries {
+			labels = MetricLabels{metricsOther, metricsOther, metricsOther, metricsOther}
+			s = m.series[labels]
+		}
+		if s == nil {
+			s = &MetricSeries{MetricLabels: labels, FirstSeen: now}
+			m.series[labels] = s
+		}
+	}
+	s.Count++
+	s.LastSeen = now
+}
+
+// metricLabels returns the labels of n, whose code is the one of the innermost
+// definition and whose outer code is the one of the outermost definition.
+func metricLabels(n *node) MetricLabels {
+	var labels MetricLabels
+	for err := error(n); err != nil; {
+		var code string
+		var next error
+		switch e := err.(type) {
+		case *node:
+			if e.underlying != nil {
+				code = e.underlying.Code()
+			}
+			next = e.cause
+		case BatchErrors:
+			// the reason wrapping a batch describes it, see (*node).Code
+			if labels.Code == "" {
+				code = e.Code()
+			}
+		case Message:
+			code = e.Code()
+		}
+		if code != "" {
+			if labels.OuterCode == "" {
+				labels.OuterCode = code
+			}
+			labels.Code = code
+		}
+		err = next
+	}
+	for _, code := range []*string{&labels.Code, &labels.OuterCode} {
+		if *code != "" && !isDefinedCode(*code) {
+			*code = metricsOther
+		}
+	}
+	if len(n.tracer.stack) > 0 {
+		frame, _ := runtime.CallersFrames(n.tracer.stack[:1]).Next()
+		labels.Package = funcPackage(frame.Function)
+	}
+	if severity, ok := Meta(n, MetaSeverity); ok {
+		labels.Severity = severity
+	} else {
+		labels.Severity = metricsDefaultSeverity
+	}
+	return labels
+}
+
+// Snapshot returns the series counted so far, sorted by labels.
+func (m *Metrics) Snapshot() []MetricSeries {
+	m.mu.Lock()
+	var series = make([]MetricSeries, 0, len(m.series))
+	for _, s := range m.series {
+		series = append(series, *s)
+	}
+	m.mu.Unlock()
+	sort.Slice(series, func(i, j int) bool {
+		var a, b = series[i].MetricLabels, series[j].MetricLabels
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.OuterCode != b.OuterCode {
+			return a.OuterCode < b.OuterCode
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Severity < b.Severity
+	})
+	return series
+}
+
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	m.series = make(map[MetricLabels]*MetricSeries)
+	m.mu.Unlock()
+}
+
+// Var returns the series as an expvar variable, to be published with
+// expvar.Publish.
+func (m *Metrics) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return m.Snapshot()
+	})
+}
+
+// ServeHTTP writes the series in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	var series = m.Snapshot()
+	var b strings.Builder
+	var write = func(name, typ, help string, value func(s MetricSeries) string) {
+		name = m.opts.Namespace + "_" + name
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, s := range series {
+			fmt.Fprintf(&b, "%s{code=%s,outer_code=%s,package=%s,severity=%s} %s\n", name,
+				promLabel(s.Code), promLabel(s.OuterCode), promLabel(s.Package), promLabel(s.Severity), value(s))
+		}
+	}
+	write("occurrences_total", "counter", "Number of traced errors.", func(s MetricSeries) string {
+		return fmt.Sprint(s.Count)
+	})
+	write("first_seen_timestamp_seconds", "gauge", "Time of the first traced error.", func(s MetricSeries) string {
+		return promTimestamp(s.FirstSeen)
+	})
+	write("last_seen_timestamp_seconds", "gauge", "Time of the last traced error.", func(s MetricSeries) string {
+		return promTimestamp(s.LastSeen)
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func promLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func promTimestamp(t time.Time) string {
+	return fmt.Sprintf("%.3f", float64(t.UnixNano())/1e9)
+}
@@ -0,0 +1,97 @@
+package errors_test
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var (
+	errMetrics      = errors.New("METRICS0001", "metrics failed", errors.WithSeverity("warning"))
+	errMetricsOuter = errors.New("METRICS0002", "metrics outer")
+)
+
+// observe returns a registry counting the errors it traces in m.
+func observe(m *errors.Metrics) *errors.Registry {
+	var r = errors.NewRegistry()
+	r.OnTrace(m.Observe)
+	return r
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var m = errors.NewMetrics(errors.MetricsOptions{Namespace: "app"})
+	var r = observe(m)
+	var inner = r.Build(errMetrics).Cause(fmt.Errorf("a")).Err()
+	r.Build(errMetricsOuter).Cause(inner).Err()
+	r.Build(errMetrics).Cause(fmt.Errorf("b")).Err()
+
+	var b strings.Builder
+	if err := m.WritePrometheus(&b); err != nil {
+		t.Fatal(err)
+	}
+	var lines = strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	var want = []string{
+		`# HELP app_occurrences_total Number of traced errors.`,
+		`# TYPE app_occurrences_total counter`,
+		`app_occurrences_total{code="METRICS0001",outer_code="METRICS0001",package="github.com/lipence/errors_test",severity="warning"} 2`,
+		`app_occurrences_total{code="METRICS0001",outer_code="METRICS0002",package="github.com/lipence/errors_test",severity="warning"} 1`,
+		`# HELP app_first_seen_timestamp_seconds Time of the first traced error.`,
+		`# TYPE app_first_seen_timestamp_seconds gauge`,
+	}
+	if len(lines) != 12 {
+		t.Fatalf("got %d lines, want 12:\n%s", len(lines), b.String())
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %s, want %s", i, lines[i], line)
+		}
+	}
+	var timestamp = regexp.MustCompile(`^app_(first|last)_seen_timestamp_seconds\{code="METRICS000[12]",.*\} \d+\.\d{3}$`)
+	for _, i := range []int{6, 7, 10, 11} {
+		if !timestamp.MatchString(lines[i]) {
+			t.Errorf("line %d = %s, want a timestamp sample", i, lines[i])
+		}
+	}
+}
+
+func TestMetricsMaxSeries(t *testing.T) {
+	var m = errors.NewMetrics(errors.MetricsOptions{MaxSeries: 1})
+	var r = observe(m)
+	r.Build(errMetrics).Cause(fmt.Errorf("a")).Err()
+	r.Build(errMetricsOuter).Cause(fmt.Errorf("b")).Err()
+	r.Build(errMetricsOuter).Cause(fmt.Errorf("c")).Err()
+	r.Build(errMetrics).Cause(fmt.Errorf("d")).Err()
+
+	var series = m.Snapshot()
+	if len(series) != 2 {
+		t.Fatalf("got %d series, want 2: %+v", len(series), series)
+	}
+	if s := series[0]; s.Code != "METRICS0001" || s.Count != 2 {
+		t.Errorf("first series = %+v, want METRICS0001 counted twice", s)
+	}
+	var other = errors.MetricLabels{Code: "other", OuterCode: "other", Package: "other", Severity: "other"}
+	if s := series[1]; s.MetricLabels != other || s.Count != 2 {
+		t.Errorf("overflow series = %+v, want other counted twice", s)
+	}
+}
+
+func TestMetricsUnknownCode(t *testing.T) {
+	var m = errors.NewMetrics(errors.MetricsOptions{})
+	var r = observe(m)
+	r.Build(&ptrReason{"UNKNOWN0001"}).Cause(fmt.Errorf("a")).Err()
+	r.Build(errMetricsOuter).Cause(errors.Raise(&ptrReason{"UNKNOWN0002"}, nil)).Err()
+
+	var series = m.Snapshot()
+	if len(series) != 2 {
+		t.Fatalf("got %d series, want 2: %+v", len(series), series)
+	}
+	if s := series[0]; s.Code != "other" || s.OuterCode != "METRICS0002" {
+		t.Errorf("wrapped unknown code series = %+v, want code other and outer code METRICS0002", s.MetricLabels)
+	}
+	if s := series[1]; s.Code != "other" || s.OuterCode != "other" || s.Count != 1 {
+		t.Errorf("unknown code series = %+v, want code and outer code other", s)
+	}
+}
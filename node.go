@@ -8,8 +8,13 @@ import (
 type node struct {
 	tracer
 	data       []Field
-	underlying *underlying
+	underlying Reason
 	cause      error
+	text       string
+	tags       []string
+	public     string
+	reg        *Registry
+	classified bool // underlying was given by a classifier, see Classify
 }
 
 func (e *node) clone() *node {
@@ -18,14 +23,27 @@ func (e *node) clone() *node {
 		data:       e.data,
 		underlying: e.underlying,
 		cause:      e.cause,
+		text:       e.text,
+		tags:       e.tags,
+		public:     e.public,
+		reg:        e.reg,
+		classified: e.classified,
 	}
 }
 
+func (e *node) registry() *Registry {
+	return registryOf(e.reg)
+}
+
 func (e *node) Is(target error) bool {
 	if targetNode, ok := target.(*node); ok {
-		return Is(e.Underlying(), targetNode.Underlying())
+		target = targetNode.Underlying()
 	}
-	return Is(e.Underlying(), target)
+	if causeNode, ok := e.cause.(*node); ok && e.underlying == nil {
+		return causeNode.Is(target)
+	}
+	// classified errors are still their cause, e.g. io.EOF
+	return Is(e.Underlying(), target) || e.classified && Is(e.cause, target)
 }
 
 func (e *node) CausedBy(target error, deepFirst bool) (bool, error) {
@@ -52,12 +70,30 @@ func (e *node) Error() string {
 	return e.message()
 }
 
+// causeMessage returns the cause of e when its code and message take
+// precedence over the ones of the reason of e. The code and message of a
+// batch describe the batch as a whole, they only apply to errors wrapping it
+// without a reason, see batchMessage.
+func (e *node) causeMessage() (Message, bool) {
+	if e.cause == nil || e.cause == (*node)(nil) {
+		return nil, false
+	}
+	if _, isBatch := e.cause.(BatchErrors); isBatch {
+		return nil, false
+	}
+	cm, ok := e.cause.(Message)
+	return cm, ok
+}
+
+func (e *node) batchMessage() (Message, bool) {
+	batch, ok := e.cause.(BatchErrors)
+	return batch, ok
+}
+
 func (e *node) Code() (code string) {
-	if e.cause != nil && e.cause != (*node)(nil) {
-		if cm, ok := e.cause.(Message); ok {
-			if code = cm.Code(); code != "" {
-				return code
-			}
+	if cm, ok := e.causeMessage(); ok {
+		if code = cm.Code(); code != "" {
+			return code
 		}
 	}
 	if e.underlying != nil {
@@ -65,15 +101,16 @@ func (e *node) Code() (code string) {
 			return code
 		}
 	}
+	if bm, ok := e.batchMessage(); ok {
+		return bm.Code()
+	}
 	return ""
 }
 
 func (e *node) Message() (message string) {
-	if e.cause != nil && e.cause != (*node)(nil) {
-		if cm, ok := e.cause.(Message); ok {
-			if message = cm.Message(); message != "" {
-				return message
-			}
+	if cm, ok := e.causeMessage(); ok {
+		if message = cm.Message(); message != "" {
+			return message
 		}
 	}
 	if e.underlying != nil {
@@ -81,13 +118,17 @@ func (e *node) Message() (message string) {
 			return message
 		}
 	}
+	if bm, ok := e.batchMessage(); ok {
+		return bm.Message()
+	}
 	return ""
 }
 
 func (e *node) DataMap() map[string]interface{} {
 	var me = zapcore.NewMapObjectEncoder()
-	for i := 0; i < len(e.data); i++ {
-		e.data[i].AddTo(me)
+	var data = e.registry().redact(e.data)
+	for i := 0; i < len(data); i++ {
+		data[i].AddTo(me)
 	}
 	return me.Fields
 }
@@ -104,9 +145,7 @@ func (e *node) Data(key string, r bool) (val interface{}, found bool) {
 		if e.data[i].Key != key {
 			continue
 		}
-		var me = zapcore.NewMapObjectEncoder()
-		e.data[i].AddTo(me)
-		return me.Fields[key], true
+		return fieldValue(e.registry().redact(e.data[i : i+1])[0]), true
 	}
 	return nil, false
 }
@@ -132,9 +171,10 @@ func (e *node) Underlying() error {
 		return e.underlying
 	}
 	if e.cause != nil {
-		if _, ok := e.cause.(*node); !ok {
-			return e.cause
+		if causeNode, ok := e.cause.(*node); ok {
+			return causeNode.Underlying()
 		}
+		return e.cause
 	}
 	return nil
 }
@@ -164,9 +204,13 @@ func (d nodeData) MarshalJSON() (dst []byte, err error) {
 }
 
 type nodeInfoItem struct {
-	Underlying error           `json:"underlying"`
-	Data       nodeData        `json:"data,omitempty"`
-	StackTrace []traceInfoItem `json:"stackTrace,omitempty"`
+	Underlying error             `json:"underlying,omitempty"`
+	Note       string            `json:"note,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
+	Aliases    []string          `json:"aliases,omitempty"`
+	Data       nodeData          `json:"data,omitempty"`
+	StackTrace []traceInfoItem   `json:"stackTrace,omitempty"`
 }
 
 func (e *node) InfoStack(parent *node) []nodeInfoItem {
@@ -178,10 +222,10 @@ func (e *node) InfoStack(parent *node) []nodeInfoItem {
 	if e.cause != nil {
 		if causeNode, ok := e.cause.(*node); ok {
 			stack = causeNode.InfoStack(e)
-		} else if e.underlying != nil {
-			stack = append(stack, nodeInfoItem{
-				Underlying: toJSONMarshalable(e.cause),
-			})
+		} else if e.underlying != nil || e.text != "" {
+			var causeItem = nodeInfoItem{Underlying: toJSONMarshalable(e.cause)}
+			causeItem.addMeta(e.cause, e.registry())
+			stack = append(stack, causeItem)
 		} else {
 			nodeItem = nodeInfoItem{Underlying: toJSONMarshalable(e.cause)}
 		}
@@ -189,8 +233,14 @@ func (e *node) InfoStack(parent *node) []nodeInfoItem {
 	if e.underlying != nil {
 		nodeItem = nodeInfoItem{Underlying: toJSONMarshalable(e.underlying)}
 	}
+	if def, ok := nodeItem.Underlying.(*underlying); ok && def != nil {
+		nodeItem.Aliases = def.Aliases()
+	}
+	nodeItem.Note = e.text
+	nodeItem.Tags = e.tags
+	nodeItem.addMeta(nodeItem.Underlying, e.registry())
 	if len(e.data) > 0 {
-		nodeItem.Data = e.data
+		nodeItem.Data = e.registry().redact(e.data)
 	}
 	if parent != nil {
 		nodeItem.StackTrace = e.tracer.InfoStack(&parent.tracer)
@@ -201,6 +251,25 @@ func (e *node) InfoStack(parent *node) []nodeInfoItem {
 	return stack
 }
 
+// addMeta adds the tags and metadata of the definition of the item when
+// enabled by RenderMeta.
+func (i *nodeInfoItem) addMeta(reason error, r *Registry) {
+	var def, ok = reason.(*underlying)
+	if !ok || def == nil || !r.rendersMeta() {
+		return
+	}
+	for _, tag := range def.Tags() {
+		var dup bool
+		for _, t := range i.Tags {
+			dup = dup || t == tag
+		}
+		if !dup {
+			i.Tags = append(i.Tags[:len(i.Tags):len(i.Tags)], tag)
+		}
+	}
+	i.Meta = def.MetaMap()
+}
+
 func (e *node) message() string {
 	var b = getBytesBuffer()
 	defer returnBytesBuffer(b)
@@ -209,6 +278,10 @@ func (e *node) message() string {
 			b.WriteRune('\n')
 			b.WriteString(infoItem.Underlying.Error())
 		}
+		if infoItem.Note != "" {
+			b.WriteRune('\n')
+			b.WriteString(infoItem.Note)
+		}
 		if len(infoItem.Data) > 0 {
 			b.Write([]byte{':', '\x20'})
 			if data, err := marshalJSONWithoutEscape(infoItem.Data); err == nil {
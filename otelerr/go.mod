@@ -0,0 +1,22 @@
+module github.com/lipence/errors/otelerr
+
+go 1.18
+
+require (
+	github.com/lipence/errors v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+	go.uber.org/zap v1.21.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.uber.org/atomic v1.9.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+)
+
+// The library is resolved from this checkout until both modules are tagged.
+replace github.com/lipence/errors => ../
@@ -0,0 +1,245 @@
+package otelerr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lipence/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	LayerEventName = "exception.layer"
+
+	LayerIndexKey = attribute.Key("exception.layer.index")
+	LayerCauseKey = attribute.Key("exception.layer.cause")
+)
+
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// CtxExtractor extracts the trace and span IDs of the span context of ctx, if
+// it is valid. It is meant to be registered with errors.OnNoteCtx.
+func CtxExtractor(ctx context.Context) []errors.Field {
+	var sc = trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []errors.Field{
+		errors.String(TraceIDKey, sc.TraceID().String()),
+		errors.String(SpanIDKey, sc.SpanID().String()),
+	}
+}
+
+// RecordError records err on span as an exception event, followed by one
+// event per layer of the error chain, and sets the span status to Error.
+func RecordError(span trace.Span, err error, opts ...trace.EventOption) {
+	if err == nil || span == nil || !span.IsRecording() {
+		return
+	}
+	var layers = errors.Layers(err)
+	var stacktrace string
+	for _, layer := range layers {
+		if len(layer.Stack) > 0 {
+			stacktrace = Stacktrace(layer)
+			break
+		}
+	}
+	var message = exceptionMessage(err)
+	span.AddEvent(semconv.ExceptionEventName, append(opts, trace.WithAttributes(
+		semconv.ExceptionType(exceptionType(err)),
+		semconv.ExceptionMessage(message),
+		semconv.ExceptionStacktrace(stacktrace),
+	))...)
+	for i, layer := range layers {
+		var attrs = make([]attribute.KeyValue, 0, len(layer.Data)+5)
+		attrs = append(attrs,
+			LayerIndexKey.Int(i),
+			semconv.ExceptionType(layerType(layer)),
+			semconv.ExceptionMessage(layer.Message()),
+		)
+		if i > 0 {
+			attrs = append(attrs, LayerCauseKey.Int(i-1))
+		}
+		if len(layer.Stack) > 0 {
+			attrs = append(attrs, semconv.ExceptionStacktrace(Stacktrace(layer)))
+		}
+		for _, field := range layer.Data {
+			if attr, ok := Attribute(field); ok {
+				attrs = append(attrs, attr)
+				span.SetAttributes(attr)
+			}
+		}
+		span.AddEvent(LayerEventName, append(opts, trace.WithAttributes(attrs...))...)
+	}
+	span.SetStatus(codes.Error, message)
+}
+
+// Stacktrace renders the frames of layer in the format of a goroutine dump.
+func Stacktrace(layer errors.Layer) string {
+	var b strings.Builder
+	for _, frame := range layer.Frames() {
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Attribute converts a data field to a span attribute, preserving the field
+// type where OpenTelemetry has an equivalent one.
+func Attribute(field errors.Field) (attribute.KeyValue, bool) {
+	var key = attribute.Key(field.Key)
+	switch field.Type {
+	case zapcore.UnknownType, zapcore.SkipType, zapcore.NamespaceType:
+		return attribute.KeyValue{}, false
+	case zapcore.BoolType:
+		return key.Bool(field.Integer == 1), true
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type,
+		zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type:
+		return key.Int64(field.Integer), true
+	case zapcore.Uint64Type, zapcore.UintptrType:
+		if uint64(field.Integer) > math.MaxInt64 {
+			return key.String(strconv.FormatUint(uint64(field.Integer), 10)), true
+		}
+		return key.Int64(field.Integer), true
+	case zapcore.Float64Type:
+		return key.Float64(math.Float64frombits(uint64(field.Integer))), true
+	case zapcore.Float32Type:
+		return key.Float64(float64(math.Float32frombits(uint32(field.Integer)))), true
+	case zapcore.DurationType:
+		return key.String(time.Duration(field.Integer).String()), true
+	case zapcore.StringType:
+		return key.String(field.String), true
+	case zapcore.ByteStringType:
+		return key.String(string(field.Interface.([]byte))), true
+	case zapcore.ErrorType:
+		return key.String(field.Interface.(error).Error()), true
+	case zapcore.StringerType:
+		return key.String(field.Interface.(fmt.Stringer).String()), true
+	}
+	var encoder = zapcore.NewMapObjectEncoder()
+	field.AddTo(encoder)
+	switch v := encoder.Fields[field.Key].(type) {
+	case time.Time:
+		return key.String(v.Format(time.RFC3339Nano)), true
+	case string:
+		return key.String(v), true
+	case []interface{}:
+		if attr, ok := sliceAttribute(key, v); ok {
+			return attr, true
+		}
+	case nil:
+		if len(encoder.Fields) == 0 {
+			return attribute.KeyValue{}, false
+		}
+	}
+	if data, err := json.Marshal(encoder.Fields[field.Key]); err == nil {
+		return key.String(string(data)), true
+	}
+	return key.String(fmt.Sprint(encoder.Fields[field.Key])), true
+}
+
+func sliceAttribute(key attribute.Key, values []interface{}) (attribute.KeyValue, bool) {
+	if len(values) == 0 {
+		return attribute.KeyValue{}, false
+	}
+	switch values[0].(type) {
+	case bool:
+		var s = make([]bool, len(values))
+		for i, v := range values {
+			var ok bool
+			if s[i], ok = v.(bool); !ok {
+				return attribute.KeyValue{}, false
+			}
+		}
+		return key.BoolSlice(s), true
+	case string:
+		var s = make([]string, len(values))
+		for i, v := range values {
+			var ok bool
+			if s[i], ok = v.(string); !ok {
+				return attribute.KeyValue{}, false
+			}
+		}
+		return key.StringSlice(s), true
+	case float64, float32:
+		var s = make([]float64, len(values))
+		for i, v := range values {
+			switch f := v.(type) {
+			case float64:
+				s[i] = f
+			case float32:
+				s[i] = float64(f)
+			default:
+				return attribute.KeyValue{}, false
+			}
+		}
+		return key.Float64Slice(s), true
+	case int, int64, int32, int16, int8, uint32, uint16, uint8:
+		var s = make([]int64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case int:
+				s[i] = int64(n)
+			case int64:
+				s[i] = n
+			case int32:
+				s[i] = int64(n)
+			case int16:
+				s[i] = int64(n)
+			case int8:
+				s[i] = int64(n)
+			case uint32:
+				s[i] = int64(n)
+			case uint16:
+				s[i] = int64(n)
+			case uint8:
+				s[i] = int64(n)
+			default:
+				return attribute.KeyValue{}, false
+			}
+		}
+		return key.Int64Slice(s), true
+	}
+	return attribute.KeyValue{}, false
+}
+
+func exceptionType(err error) string {
+	if m, ok := err.(errors.Message); ok {
+		if code := m.Code(); code != "" {
+			return code
+		}
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+func layerType(layer errors.Layer) string {
+	if layer.Err == nil {
+		return "note"
+	}
+	return exceptionType(layer.Err)
+}
+
+func exceptionMessage(err error) string {
+	if m, ok := err.(errors.Message); ok {
+		if message := m.Message(); message != "" {
+			return message
+		}
+	}
+	return err.Error()
+}
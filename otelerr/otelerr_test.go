@@ -0,0 +1,212 @@
+package otelerr_test
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lipence/errors"
+	"github.com/lipence/errors/otelerr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+var errRecord = errors.New("OTEL0001", "record failed")
+
+func record(t *testing.T, err error) tracetest.SpanStub {
+	t.Helper()
+	var exporter = tracetest.NewInMemoryExporter()
+	var provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+	_, span := provider.Tracer("otelerr").Start(context.Background(), "test")
+	otelerr.RecordError(span, err)
+	span.End()
+	var spans = exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	return spans[0]
+}
+
+func attrs(kvs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	var m = make(map[attribute.Key]attribute.Value, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestRecordError(t *testing.T) {
+	var err = errors.Because(errRecord, fmt.Errorf("disk full"), errors.Int("attempt", 3))
+	var span = record(t, err)
+
+	if len(span.Events) != 3 {
+		t.Fatalf("got %d events, want 3", len(span.Events))
+	}
+	var exception = span.Events[0]
+	if exception.Name != semconv.ExceptionEventName {
+		t.Fatalf("first event is %q, want %q", exception.Name, semconv.ExceptionEventName)
+	}
+	var ex = attrs(exception.Attributes)
+	if got := ex[semconv.ExceptionTypeKey].AsString(); got != "OTEL0001" {
+		t.Errorf("exception.type = %q, want OTEL0001", got)
+	}
+	if got := ex[semconv.ExceptionMessageKey].AsString(); got != "record failed" {
+		t.Errorf("exception.message = %q, want %q", got, "record failed")
+	}
+	if got := ex[semconv.ExceptionStacktraceKey].AsString(); !strings.Contains(got, "TestRecordError") {
+		t.Errorf("exception.stacktrace does not contain the test function:\n%s", got)
+	}
+
+	var cause = attrs(span.Events[1].Attributes)
+	if span.Events[1].Name != otelerr.LayerEventName {
+		t.Errorf("second event is %q, want %q", span.Events[1].Name, otelerr.LayerEventName)
+	}
+	if got := cause[otelerr.LayerIndexKey].AsInt64(); got != 0 {
+		t.Errorf("cause layer index = %d, want 0", got)
+	}
+	if _, ok := cause[otelerr.LayerCauseKey]; ok {
+		t.Errorf("innermost layer has a cause attribute")
+	}
+	if got := cause[semconv.ExceptionTypeKey].AsString(); got != "*errors.errorString" {
+		t.Errorf("cause layer type = %q, want *errors.errorString", got)
+	}
+	if got := cause[semconv.ExceptionMessageKey].AsString(); got != "disk full" {
+		t.Errorf("cause layer message = %q, want %q", got, "disk full")
+	}
+
+	var outer = attrs(span.Events[2].Attributes)
+	if got := outer[otelerr.LayerIndexKey].AsInt64(); got != 1 {
+		t.Errorf("outer layer index = %d, want 1", got)
+	}
+	if got := outer[otelerr.LayerCauseKey].AsInt64(); got != 0 {
+		t.Errorf("outer layer cause = %d, want 0", got)
+	}
+	if got := outer[semconv.ExceptionTypeKey].AsString(); got != "OTEL0001" {
+		t.Errorf("outer layer type = %q, want OTEL0001", got)
+	}
+	if got := outer["attempt"].AsInt64(); got != 3 {
+		t.Errorf("outer layer attempt = %d, want 3", got)
+	}
+	if _, ok := outer[semconv.ExceptionStacktraceKey]; !ok {
+		t.Errorf("outer layer has no stacktrace")
+	}
+
+	if got := attrs(span.Attributes)["attempt"].AsInt64(); got != 3 {
+		t.Errorf("span attempt = %d, want 3", got)
+	}
+	if span.Status.Code != codes.Error || span.Status.Description != "record failed" {
+		t.Errorf("status = %v %q, want Error %q", span.Status.Code, span.Status.Description, "record failed")
+	}
+}
+
+func TestRecordNote(t *testing.T) {
+	var span = record(t, errors.Notef(errors.Raise(errRecord, nil), "loading %s", "config"))
+	var layers = span.Events[1:]
+	if len(layers) != 2 {
+		t.Fatalf("got %d layer events, want 2", len(layers))
+	}
+	var note = attrs(layers[1].Attributes)
+	if got := note[semconv.ExceptionTypeKey].AsString(); got != "note" {
+		t.Errorf("note layer type = %q, want note", got)
+	}
+	if got := note[semconv.ExceptionMessageKey].AsString(); got != "loading config" {
+		t.Errorf("note layer message = %q, want %q", got, "loading config")
+	}
+}
+
+func TestRecordNil(t *testing.T) {
+	var span = record(t, nil)
+	if len(span.Events) != 0 || span.Status.Code != codes.Unset {
+		t.Errorf("nil error recorded %d events and status %v", len(span.Events), span.Status.Code)
+	}
+}
+
+type stringer struct{}
+
+func (stringer) String() string { return "stringer" }
+
+func TestAttribute(t *testing.T) {
+	var now = time.Date(2023, 4, 5, 6, 7, 8, 9, time.UTC)
+	var tests = []struct {
+		field errors.Field
+		want  attribute.Value
+	}{
+		{errors.Bool("v", true), attribute.BoolValue(true)},
+		{errors.Int("v", -4), attribute.Int64Value(-4)},
+		{errors.Int8("v", 8), attribute.Int64Value(8)},
+		{errors.Uint32("v", 32), attribute.Int64Value(32)},
+		{errors.Uint64("v", 64), attribute.Int64Value(64)},
+		{errors.Uint64("v", math.MaxUint64), attribute.StringValue("18446744073709551615")},
+		{errors.Float64("v", 1.5), attribute.Float64Value(1.5)},
+		{errors.Float32("v", 0.25), attribute.Float64Value(0.25)},
+		{errors.Duration("v", 1500*time.Millisecond), attribute.StringValue("1.5s")},
+		{errors.String("v", "s"), attribute.StringValue("s")},
+		{errors.ByteString("v", []byte("b")), attribute.StringValue("b")},
+		{errors.NamedError("v", fmt.Errorf("e")), attribute.StringValue("e")},
+		{errors.Stringer("v", stringer{}), attribute.StringValue("stringer")},
+		{errors.Time("v", now), attribute.StringValue("2023-04-05T06:07:08.000000009Z")},
+		{errors.Bools("v", []bool{true, false}), attribute.BoolSliceValue([]bool{true, false})},
+		{errors.Strings("v", []string{"a", "b"}), attribute.StringSliceValue([]string{"a", "b"})},
+		{errors.Ints("v", []int{1, 2}), attribute.Int64SliceValue([]int64{1, 2})},
+		{errors.Float64s("v", []float64{0.5}), attribute.Float64SliceValue([]float64{0.5})},
+		{errors.Any("v", map[string]int{"a": 1}), attribute.StringValue(`{"a":1}`)},
+	}
+	for _, test := range tests {
+		attr, ok := otelerr.Attribute(test.field)
+		if !ok {
+			t.Errorf("%v: not converted", test.field.Type)
+			continue
+		}
+		if attr.Key != "v" {
+			t.Errorf("%v: key = %q, want v", test.field.Type, attr.Key)
+		}
+		if attr.Value.Type() != test.want.Type() || attr.Value.Emit() != test.want.Emit() {
+			t.Errorf("%v: value = %v (%v), want %v (%v)", test.field.Type,
+				attr.Value.Emit(), attr.Value.Type(), test.want.Emit(), test.want.Type())
+		}
+	}
+
+	for _, field := range []errors.Field{errors.Skip(), errors.Namespace("ns")} {
+		if _, ok := otelerr.Attribute(field); ok {
+			t.Errorf("%v: converted, want skipped", field.Type)
+		}
+	}
+}
+
+func TestRecordBatchCause(t *testing.T) {
+	var batch = errors.Batch([]error{fmt.Errorf("a"), fmt.Errorf("b")})
+	var span = record(t, errors.Because(errRecord, batch))
+	if got := attrs(span.Events[0].Attributes)[semconv.ExceptionTypeKey].AsString(); got != "OTEL0001" {
+		t.Errorf("exception.type = %q, want OTEL0001", got)
+	}
+	if span.Status.Description != "record failed" {
+		t.Errorf("status description = %q, want %q", span.Status.Description, "record failed")
+	}
+}
+
+func TestCtxExtractor(t *testing.T) {
+	defer errors.OnNoteCtx(otelerr.CtxExtractor)()
+	var provider = sdktrace.NewTracerProvider()
+	defer provider.Shutdown(context.Background())
+	ctx, span := provider.Tracer("otelerr").Start(context.Background(), "test")
+	defer span.End()
+
+	var err = errors.BecauseCtx(ctx, errRecord, fmt.Errorf("disk full"))
+	if got, _ := errors.DataString(err, otelerr.TraceIDKey); got != span.SpanContext().TraceID().String() {
+		t.Errorf("trace_id = %q, want %q", got, span.SpanContext().TraceID())
+	}
+	if got, _ := errors.DataString(err, otelerr.SpanIDKey); got != span.SpanContext().SpanID().String() {
+		t.Errorf("span_id = %q, want %q", got, span.SpanContext().SpanID())
+	}
+
+	if err = errors.NoteCtx(context.Background(), fmt.Errorf("disk full")); errors.HasData(err, otelerr.TraceIDKey, true) {
+		t.Errorf("trace_id extracted from a context without span")
+	}
+}
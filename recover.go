@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+)
+
+var ErrPanic = NewUnderlying("panic", "recovered from panic")
+
+func Recover(errp *error, rePanic ...error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	n := fromPanic(v)
+	for _, target := range rePanic {
+		if Is(n, target) {
+			panic(v)
+		}
+	}
+	if errp != nil {
+		*errp = n
+	}
+}
+
+func Try(fn func(), rePanic ...error) (err error) {
+	defer Recover(&err, rePanic...)
+	fn()
+	return nil
+}
+
+func Catch(fn func() error, rePanic ...error) (err error) {
+	defer Recover(&err, rePanic...)
+	return fn()
+}
+
+func fromPanic(v interface{}) *node {
+	n := &node{data: []Field{Any("panic", v)}}
+	switch p := v.(type) {
+	case error:
+		n.cause = p
+	default:
+		n.underlying = ErrPanic
+	}
+	n.tracePanic(2, defaultRegistry.StackDepth())
+	defaultRegistry.runHooks(n, true, n.cause != nil)
+	return n
+}
+
+// panicFunc is the runtime function raising panics, both the ones of panic
+// and the runtime errors, and running the deferred recovery functions.
+const panicFunc = "runtime.gopanic"
+
+// tracePanic captures the stack of the panicking goroutine, dropping the
+// deferred recovery frames and the runtime frames raising the panic. The
+// innermost panicFunc frame is the one recovered, as a deferred function may
+// panic again while an outer panic is running. The stack is kept whole if no
+// panicFunc frame is found.
+func (t *tracer) tracePanic(skip, depth int) {
+	t.trace(skip+1, depth)
+	var panicking bool
+	for i := range t.stack {
+		frame, _ := runtime.CallersFrames(t.stack[i : i+1]).Next()
+		if !panicking {
+			panicking = frame.Function == panicFunc
+		} else if !strings.HasPrefix(frame.Function, "runtime.") {
+			t.stack = t.stack[i:]
+			return
+		}
+	}
+}
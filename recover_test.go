@@ -0,0 +1,122 @@
+package errors_test
+
+import (
+	sysErr "errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var errRecover = errors.New("RECOVER0001", "recovered")
+
+// panicSite returns the function of the first frame of the panic layer of err.
+func panicSite(t *testing.T, err error) string {
+	t.Helper()
+	var layers = errors.Layers(err)
+	if len(layers) == 0 {
+		t.Fatalf("no layers in %v", err)
+	}
+	var frames = layers[len(layers)-1].Frames()
+	if len(frames) == 0 {
+		t.Fatalf("no stack trace in %v", err)
+	}
+	return frames[0].Function
+}
+
+func TestTryPanicSite(t *testing.T) {
+	var tests = []struct {
+		name string
+		fn   func()
+	}{
+		{"panic", func() { panic("boom") }},
+		{"panic error", func() { panic(fmt.Errorf("boom")) }},
+		{"nil map", func() {
+			var m map[string]int
+			m["a"] = 1
+		}},
+		{"nil pointer", func() {
+			var p *struct{ v int }
+			p.v = 1
+		}},
+		{"panic in a deferred function", func() {
+			defer func() { panic("second") }()
+			panic("first")
+		}},
+	}
+	for i, test := range tests {
+		var err = errors.Try(test.fn)
+		if err == nil {
+			t.Errorf("%s: no error recovered", test.name)
+			continue
+		}
+		var want = fmt.Sprintf(".TestTryPanicSite.func%d", i+1)
+		if i == len(tests)-1 {
+			want += ".1"
+		}
+		if got := panicSite(t, err); !strings.HasSuffix(got, want) {
+			t.Errorf("%s: first frame is %s, want the panic site *%s", test.name, got, want)
+		}
+	}
+}
+
+func TestTryPanicValue(t *testing.T) {
+	var err = errors.Try(func() { panic("boom") })
+	if !errors.Is(err, errors.ErrPanic) {
+		t.Errorf("panic is not ErrPanic: %v", err)
+	}
+	if got, _ := errors.DataString(err, "panic"); got != "boom" {
+		t.Errorf("panic value = %q, want boom", got)
+	}
+
+	err = errors.Try(func() { panic(errors.Raise(errRecover, nil)) })
+	if !errors.Is(err, errRecover) {
+		t.Errorf("coded panic value is not its definition: %v", err)
+	}
+
+	var nilMap = errors.Try(func() {
+		var m map[string]int
+		m["a"] = 1
+	})
+	var runtimeErr runtime.Error
+	if !sysErr.As(nilMap, &runtimeErr) {
+		t.Errorf("runtime panic is not a runtime.Error: %v", nilMap)
+	}
+}
+
+func TestTryRePanic(t *testing.T) {
+	var value = errors.Raise(errRecover, nil)
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		_ = errors.Try(func() { panic(value) }, errRecover)
+	}()
+	if recovered != value {
+		t.Errorf("re-panicked %v, want the original panic value", recovered)
+	}
+
+	if err := errors.Try(func() { panic("boom") }, errRecover); !errors.Is(err, errors.ErrPanic) {
+		t.Errorf("panic not matching rePanic was not recovered: %v", err)
+	}
+}
+
+func TestCatch(t *testing.T) {
+	var want = errors.Raise(errRecover, nil)
+	if err := errors.Catch(func() error { return want }); err != want {
+		t.Errorf("Catch returned %v, want the error of fn", err)
+	}
+	if err := errors.Catch(func() error { panic("boom") }); !errors.Is(err, errors.ErrPanic) {
+		t.Errorf("Catch did not recover the panic: %v", err)
+	}
+
+	var err error
+	func() {
+		defer errors.Recover(&err)
+		panic("boom")
+	}()
+	if !errors.Is(err, errors.ErrPanic) || !strings.HasSuffix(panicSite(t, err), ".TestCatch.func3") {
+		t.Errorf("Recover did not trace the panic site: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package errors
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type MaskStyle int
+
+const (
+	MaskFull MaskStyle = iota
+	MaskLast4
+	MaskHash
+)
+
+const fullMask = "******"
+
+// processHashKey keys MaskHash when the redaction policy has no HashKey, so
+// that hashes can be correlated within a process only.
+var processHashKey = func() []byte {
+	var key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// Mask masks value, MaskHash being keyed with a random key of the process.
+func (s MaskStyle) Mask(value string) string {
+	return s.mask(value, processHashKey)
+}
+
+func (s MaskStyle) mask(value string, hashKey []byte) string {
+	switch s {
+	case MaskLast4:
+		var runes = []rune(value)
+		if len(runes) <= 4 {
+			return strings.Repeat("*", len(runes))
+		}
+		return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+	case MaskHash:
+		var mac = hmac.New(sha256.New, hashKey)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	default:
+		return fullMask
+	}
+}
+
+// RedactRule masks the whole value of fields whose key matches Key, or the
+// parts of string values matching Value and accepted by Check.
+type RedactRule struct {
+	Key   *regexp.Regexp
+	Value *regexp.Regexp
+	Check func(match string) bool
+	Style MaskStyle
+}
+
+// RedactPolicy holds the rules masking data fields. MaskHash renders an
+// HMAC-SHA256 keyed with HashKey, so that masked values can be correlated
+// without being recovered by hashing candidates: PANs and emails are too
+// predictable for a plain hash. HashKey must be kept secret and shared by the
+// processes whose output is correlated, a random key of the process is used
+// when it is empty.
+type RedactPolicy struct {
+	Rules       []RedactRule
+	SecretStyle MaskStyle
+	HashKey     []byte
+}
+
+func (p *RedactPolicy) mask(style MaskStyle, value string) string {
+	if len(p.HashKey) == 0 {
+		return style.Mask(value)
+	}
+	return style.mask(value, p.HashKey)
+}
+
+func KeyRule(expr string, style MaskStyle) RedactRule {
+	return RedactRule{Key: regexp.MustCompile(expr), Style: style}
+}
+
+func ValueRule(expr string, style MaskStyle) RedactRule {
+	return RedactRule{Value: regexp.MustCompile(expr), Style: style}
+}
+
+func PANRule(style MaskStyle) RedactRule {
+	return RedactRule{
+		Value: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Check: luhnValid,
+		Style: style,
+	}
+}
+
+func EmailRule(style MaskStyle) RedactRule {
+	return ValueRule(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`, style)
+}
+
+func luhnValid(number string) bool {
+	var sum, digits int
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+func (r *Registry) SetRedactPolicy(policy *RedactPolicy) {
+	if policy == nil {
+		policy = &RedactPolicy{}
+	}
+	r.redactPolicy.Store(policy)
+}
+
+func (r *Registry) RedactPolicy() *RedactPolicy {
+	return r.redactPolicy.Load().(*RedactPolicy)
+}
+
+func SetRedactPolicy(policy *RedactPolicy) {
+	defaultRegistry.SetRedactPolicy(policy)
+}
+
+type secret struct {
+	value interface{}
+}
+
+// String masks the secret with the policy of the default registry, the data
+// of errors are masked with the policy of their registry before rendering.
+func (s secret) String() string {
+	return defaultRegistry.RedactPolicy().maskSecret(s)
+}
+
+func (p *RedactPolicy) maskSecret(s secret) string {
+	return p.mask(p.SecretStyle, fmt.Sprint(s.value))
+}
+
+// Secret marks a data field as sensitive: its value is always masked when
+// rendered, and can only be read back through Reveal.
+func Secret(key string, val interface{}) Field {
+	return Field{Key: key, Type: zapcore.StringerType, Interface: secret{val}}
+}
+
+func Reveal(src error, key string, r bool) (interface{}, bool) {
+	if srcNode, ok := src.(*node); ok {
+		return srcNode.reveal(key, r)
+	}
+	return nil, false
+}
+
+func (e *node) reveal(key string, r bool) (val interface{}, found bool) {
+	if r {
+		if causeNode, ok := e.cause.(*node); ok {
+			if val, found = causeNode.reveal(key, r); found {
+				return val, true
+			}
+		}
+	}
+	for i := 0; i < len(e.data); i++ {
+		if e.data[i].Key != key {
+			continue
+		}
+		if s, ok := e.data[i].Interface.(secret); ok {
+			return s.value, true
+		}
+		return fieldValue(e.data[i]), true
+	}
+	return nil, false
+}
+
+func fieldValue(field Field) interface{} {
+	var me = zapcore.NewMapObjectEncoder()
+	field.AddTo(me)
+	return me.Fields[field.Key]
+}
+
+// redact returns fields with the policy of r applied, sharing the backing
+// array with fields when nothing has to be masked.
+func (r *Registry) redact(fields []Field) []Field {
+	var policy = r.RedactPolicy()
+	var redacted []Field
+	for i := 0; i < len(fields); i++ {
+		if masked, ok := policy.redact(fields[i]); ok {
+			if redacted == nil {
+				redacted = append(make([]Field, 0, len(fields)), fields...)
+			}
+			redacted[i] = masked
+		}
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+func (p *RedactPolicy) redact(field Field) (Field, bool) {
+	if s, ok := field.Interface.(secret); ok {
+		return String(field.Key, p.maskSecret(s)), true
+	}
+	if len(p.Rules) == 0 {
+		return field, false
+	}
+	for _, rule := range p.Rules {
+		if rule.Key != nil && rule.Key.MatchString(field.Key) {
+			return String(field.Key, p.mask(rule.Style, fmt.Sprint(fieldValue(field)))), true
+		}
+	}
+	var value string
+	switch field.Type {
+	case zapcore.StringType:
+		value = field.String
+	case zapcore.ByteStringType:
+		value = string(field.Interface.([]byte))
+	case zapcore.StringerType, zapcore.ErrorType:
+		value = fmt.Sprint(fieldValue(field))
+	default:
+		return field, false
+	}
+	var masked = value
+	for _, rule := range p.Rules {
+		if rule.Value == nil {
+			continue
+		}
+		masked = rule.Value.ReplaceAllStringFunc(masked, func(match string) string {
+			if rule.Check != nil && !rule.Check(match) {
+				return match
+			}
+			return p.mask(rule.Style, match)
+		})
+	}
+	if masked == value {
+		return field, false
+	}
+	return String(field.Key, masked), true
+}
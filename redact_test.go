@@ -0,0 +1,63 @@
+package errors_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var errRedact = errors.New("REDACT0001", "redact")
+
+func TestMaskHashKeyed(t *testing.T) {
+	var key = []byte("correlation key")
+	var r = errors.NewRegistry()
+	r.SetRedactPolicy(&errors.RedactPolicy{
+		Rules:   []errors.RedactRule{errors.EmailRule(errors.MaskHash)},
+		HashKey: key,
+	})
+	var err = r.Build(errRedact).Cause(fmt.Errorf("c")).With(errors.String("user", "jane@example.com")).Err()
+
+	var mac = hmac.New(sha256.New, key)
+	mac.Write([]byte("jane@example.com"))
+	var want = "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	if got, _ := errors.Data(err, "user", true); got != want {
+		t.Errorf("masked email = %v, want %q", got, want)
+	}
+
+	var unsalted = sha256.Sum256([]byte("jane@example.com"))
+	if strings.Contains(err.Error(), hex.EncodeToString(unsalted[:8])) {
+		t.Errorf("Error() contains the unkeyed hash: %s", err)
+	}
+
+	var unkeyed = errors.MaskHash.Mask("jane@example.com")
+	if unkeyed == want || !strings.HasPrefix(unkeyed, "hmac:") {
+		t.Errorf("MaskHash.Mask without key = %q", unkeyed)
+	}
+	if again := errors.MaskHash.Mask("jane@example.com"); again != unkeyed {
+		t.Errorf("MaskHash.Mask is not stable within the process: %q != %q", again, unkeyed)
+	}
+}
+
+func TestSecretStyleOfRegistry(t *testing.T) {
+	var r = errors.NewRegistry()
+	r.SetRedactPolicy(&errors.RedactPolicy{SecretStyle: errors.MaskLast4})
+	var err = r.Build(errRedact).Cause(fmt.Errorf("c")).With(errors.Secret("card", "4111111111111111")).Err()
+
+	if got, _ := errors.Data(err, "card", true); got != "************1111" {
+		t.Errorf("Data(card) = %v, want the MaskLast4 style of the registry", got)
+	}
+	if !strings.Contains(err.Error(), "************1111") {
+		t.Errorf("Error() does not use the MaskLast4 style of the registry: %s", err)
+	}
+	if got := errors.Layers(err)[1].Data[0]; got.String != "************1111" {
+		t.Errorf("Layers data = %v, want the MaskLast4 style of the registry", got)
+	}
+	if got, _ := errors.Reveal(err, "card", true); got != "4111111111111111" {
+		t.Errorf("Reveal(card) = %v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package errors
+
+import "sync/atomic"
+
+// Registry holds the configuration applied when defining, raising and
+// rendering errors: message filters, hooks, context extractors, stack depth,
+// classifiers, redaction policy and JSON rendering options. The top-level functions use
+// the default registry, isolated registries created by NewRegistry are meant
+// for tests and libraries which must not share it. A registry is safe for
+// concurrent use.
+//
+// The definitions validated by SetCodeSchema and counted by Metrics, and the
+// code of batches set by SetBatchCode, are shared by all registries.
+type Registry struct {
+	filters    hookList
+	extractors hookList
+	traceHooks hookList
+	wrapHooks  hookList
+	batchHooks hookList
+
+	classifiers hookList
+
+	deprecationHooks hookList
+
+	redactPolicy atomic.Value
+	stackDepth   int32
+	renderMeta   int32
+}
+
+var defaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	var r = &Registry{stackDepth: maxStackDepth}
+	r.redactPolicy.Store(&RedactPolicy{})
+	return r
+}
+
+// Default returns the registry used by the top-level functions.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Clone returns an isolated registry with the configuration of r. Hooks
+// registered in r afterwards, or unregistered from it, do not apply to the
+// clone.
+func (r *Registry) Clone() *Registry {
+	var c = &Registry{
+		stackDepth: atomic.LoadInt32(&r.stackDepth),
+		renderMeta: atomic.LoadInt32(&r.renderMeta),
+	}
+	c.redactPolicy.Store(r.RedactPolicy())
+	r.filters.copyTo(&c.filters)
+	r.extractors.copyTo(&c.extractors)
+	r.traceHooks.copyTo(&c.traceHooks)
+	r.wrapHooks.copyTo(&c.wrapHooks)
+	r.batchHooks.copyTo(&c.batchHooks)
+	r.classifiers.copyTo(&c.classifiers)
+	r.deprecationHooks.copyTo(&c.deprecationHooks)
+	return c
+}
+
+// OnCreateMsg registers filter to be applied to the code and message of the
+// definitions created by r. The returned function unregisters the filter.
+func (r *Registry) OnCreateMsg(filter MsgFilter) (unregister func()) {
+	if filter == nil {
+		return func() {}
+	}
+	return r.filters.add(filter)
+}
+
+// filter applies the filters of r, which see the caller of the function
+// defining the error at runtime.Caller(3).
+func (r *Registry) filter(code, msg string) (string, string) {
+	for _, e := range r.filters.load() {
+		code, msg = e.fn.(MsgFilter)(code, msg)
+	}
+	return code, msg
+}
+
+func (r *Registry) New(code, msg string, opts ...DefOption) *underlying {
+	code, msg = r.filter(code, msg)
+	var def = &underlying{code: code, message: msg, reg: r}
+	for _, opt := range opts {
+		opt(def)
+	}
+	registerDefinition(def, 1)
+	return def
+}
+
+// Build starts building an error of reason with the configuration of r.
+func (r *Registry) Build(reason Reason) *Builder {
+	var b = Build(reason)
+	b.reg = r
+	return b
+}
+
+func (r *Registry) Batch(errs []error) error {
+	return batch(r, errs)
+}
+
+// SetStackDepth sets the maximum number of frames captured in stack traces.
+func (r *Registry) SetStackDepth(depth int) {
+	if depth <= 0 {
+		depth = maxStackDepth
+	}
+	atomic.StoreInt32(&r.stackDepth, int32(depth))
+}
+
+func (r *Registry) StackDepth() int {
+	return int(atomic.LoadInt32(&r.stackDepth))
+}
+
+// RenderMeta enables the output of definition tags and metadata in JSON.
+func (r *Registry) RenderMeta(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&r.renderMeta, v)
+}
+
+func (r *Registry) rendersMeta() bool {
+	return atomic.LoadInt32(&r.renderMeta) == 1
+}
+
+func registryOf(r *Registry) *Registry {
+	if r == nil {
+		return defaultRegistry
+	}
+	return r
+}
@@ -0,0 +1,225 @@
+package errors
+
+import (
+	"fmt"
+	sysLog "log"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type definition struct {
+	reason   *underlying
+	pkgPath  string
+	position string
+}
+
+// definitions records every definition created by New, so that a schema set
+// after package initialization still validates them. Definitions created
+// repeatedly at the same position with the same code, by New called inside a
+// function, are recorded once.
+var definitions struct {
+	sync.Mutex
+	list  []definition
+	seen  map[string]struct{} // position and code
+	codes map[string]struct{}
+}
+
+func registerDefinition(reason *underlying, skip int) {
+	var def = definition{reason: reason}
+	if pc, file, line, ok := runtime.Caller(skip + 1); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			def.pkgPath = funcPackage(fn.Name())
+		}
+		def.position = fmt.Sprintf("%s:%d", file, line)
+	}
+	definitions.Lock()
+	if definitions.codes == nil {
+		definitions.seen = make(map[string]struct{})
+		definitions.codes = make(map[string]struct{})
+	}
+	if _, seen := definitions.seen[def.key()]; !seen {
+		definitions.seen[def.key()] = struct{}{}
+		definitions.list = append(definitions.list, def)
+	}
+	definitions.codes[reason.code] = struct{}{}
+	var schema = codeSchema
+	definitions.Unlock()
+	if schema != nil {
+		schema.check(def)
+	}
+}
+
+func (d definition) key() string {
+	return d.position + "\x00" + d.reason.code
+}
+
+var selfPackagePath = reflect.TypeOf(definition{}).PkgPath()
+
+func isDefinedCode(code string) bool {
+	definitions.Lock()
+	defer definitions.Unlock()
+	_, ok := definitions.codes[code]
+	return ok
+}
+
+type SchemaAction int
+
+const (
+	SchemaPanic SchemaAction = iota
+	SchemaLog
+	SchemaCollect
+)
+
+// CodeRange reserves the numeric part of codes, i.e. their trailing digits,
+// from Min to Max for the packages under the Package path prefix.
+type CodeRange struct {
+	Owner   string
+	Package string
+	Min     uint64
+	Max     uint64
+}
+
+// CodeSchema restricts the codes passed to New. Prefixes maps package path
+// prefixes to the code prefix required in these packages, the longest
+// matching package path prefix applies.
+type CodeSchema struct {
+	Pattern   *regexp.Regexp
+	MaxLength int
+	Prefixes  map[string]string
+	Ranges    []CodeRange
+	Action    SchemaAction
+}
+
+type SchemaViolation struct {
+	Code     string
+	Message  string
+	Package  string
+	Position string
+	Reason   string
+}
+
+func (v SchemaViolation) Error() string {
+	return fmt.Sprintf("invalid error code `%s` at %s: %s", v.Code, v.Position, v.Reason)
+}
+
+var codeSchema *CodeSchema
+var schemaViolations struct {
+	sync.Mutex
+	list []SchemaViolation
+	seen map[string]struct{} // position and code
+}
+
+// SetCodeSchema validates the definitions created so far and all later ones
+// against schema, discarding the violations found by the previous schema.
+func SetCodeSchema(schema *CodeSchema) {
+	definitions.Lock()
+	codeSchema = schema
+	var defined = append([]definition(nil), definitions.list...)
+	schemaViolations.Lock()
+	schemaViolations.list = nil
+	schemaViolations.seen = nil
+	schemaViolations.Unlock()
+	definitions.Unlock()
+	if schema == nil {
+		return
+	}
+	for _, def := range defined {
+		schema.check(def)
+	}
+}
+
+// SchemaViolations returns the violations found by the current schema when
+// its action is SchemaLog or SchemaCollect.
+func SchemaViolations() []SchemaViolation {
+	schemaViolations.Lock()
+	defer schemaViolations.Unlock()
+	return append([]SchemaViolation(nil), schemaViolations.list...)
+}
+
+func (s *CodeSchema) check(def definition) {
+	var code = def.reason.code
+	// the built-in definitions of this module follow their own scheme
+	if code == "" || hasPathPrefix(def.pkgPath, selfPackagePath) {
+		return
+	}
+	var reason = s.violation(code, def.pkgPath)
+	if reason == "" {
+		return
+	}
+	var violation = SchemaViolation{
+		Code:     code,
+		Message:  def.reason.message,
+		Package:  def.pkgPath,
+		Position: def.position,
+		Reason:   reason,
+	}
+	if s.Action == SchemaPanic {
+		panic(violation)
+	}
+	schemaViolations.Lock()
+	if _, seen := schemaViolations.seen[def.key()]; seen {
+		schemaViolations.Unlock()
+		return
+	}
+	if schemaViolations.seen == nil {
+		schemaViolations.seen = make(map[string]struct{})
+	}
+	schemaViolations.seen[def.key()] = struct{}{}
+	schemaViolations.list = append(schemaViolations.list, violation)
+	schemaViolations.Unlock()
+	if s.Action == SchemaLog {
+		sysLog.Print(violation.Error())
+	}
+}
+
+func (s *CodeSchema) violation(code, pkgPath string) string {
+	if s.MaxLength > 0 && len(code) > s.MaxLength {
+		return fmt.Sprintf("longer than %d", s.MaxLength)
+	}
+	if s.Pattern != nil && !s.Pattern.MatchString(code) {
+		return fmt.Sprintf("does not match `%s`", s.Pattern)
+	}
+	var pkgPrefix, codePrefix string
+	for p, c := range s.Prefixes {
+		if hasPathPrefix(pkgPath, p) && len(p) >= len(pkgPrefix) {
+			pkgPrefix, codePrefix = p, c
+		}
+	}
+	if !strings.HasPrefix(code, codePrefix) {
+		return fmt.Sprintf("package %s requires prefix `%s`", pkgPath, codePrefix)
+	}
+	var digits = strings.TrimRight(code, "0123456789")
+	number, err := strconv.ParseUint(code[len(digits):], 10, 64)
+	if err != nil {
+		return ""
+	}
+	var owned, reserved = false, ""
+	for _, r := range s.Ranges {
+		var inRange = number >= r.Min && number <= r.Max
+		if hasPathPrefix(pkgPath, r.Package) {
+			owned = owned || inRange
+		} else if inRange && reserved == "" {
+			reserved = r.Owner
+		}
+	}
+	if owned {
+		return ""
+	}
+	if reserved != "" {
+		return fmt.Sprintf("number %d is reserved for %s", number, reserved)
+	}
+	for _, r := range s.Ranges {
+		if hasPathPrefix(pkgPath, r.Package) {
+			return fmt.Sprintf("number %d is out of the ranges of package %s", number, pkgPath)
+		}
+	}
+	return ""
+}
+
+func hasPathPrefix(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
@@ -0,0 +1,36 @@
+//go:build !debug
+// +build !debug
+
+package errors_test
+
+import (
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+// Debug builds panic on redeclared codes, so definitions are only created
+// repeatedly by release builds.
+func TestSchemaRedefinition(t *testing.T) {
+	defer errors.SetCodeSchema(nil)
+	var define = func() {
+		for i := 0; i < 3; i++ {
+			errors.New("schema-redefined", "invalid code")
+		}
+	}
+	define()
+	var defined = errors.DefinitionCount()
+	define()
+	if n := errors.DefinitionCount(); n != defined {
+		t.Errorf("definitions grew from %d to %d on repeated New calls", defined, n)
+	}
+
+	errors.SetCodeSchema(schemaTest)
+	if n := schemaViolations("schema-redefined"); n != 1 {
+		t.Errorf("got %d violations, want 1", n)
+	}
+	define()
+	if n := schemaViolations("schema-redefined"); n != 1 {
+		t.Errorf("got %d violations after redefining, want 1", n)
+	}
+}
@@ -0,0 +1,39 @@
+package errors_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+var errSchemaTest = errors.New("schema-test", "invalid code")
+
+var schemaTest = &errors.CodeSchema{Pattern: regexp.MustCompile(`^[A-Z]+\d+$`), Action: errors.SchemaCollect}
+
+// schemaViolations counts the violations of code.
+func schemaViolations(code string) (n int) {
+	for _, v := range errors.SchemaViolations() {
+		if v.Code == code {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSchemaViolationsReset(t *testing.T) {
+	defer errors.SetCodeSchema(nil)
+	var code = errSchemaTest.Code()
+	errors.SetCodeSchema(schemaTest)
+	if n := schemaViolations(code); n != 1 {
+		t.Errorf("got %d violations, want 1", n)
+	}
+	errors.SetCodeSchema(schemaTest)
+	if n := schemaViolations(code); n != 1 {
+		t.Errorf("got %d violations after setting the schema again, want 1", n)
+	}
+	errors.SetCodeSchema(nil)
+	if n := len(errors.SchemaViolations()); n != 0 {
+		t.Errorf("got %d violations without schema, want 0", n)
+	}
+}
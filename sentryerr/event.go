@@ -0,0 +1,185 @@
+package sentryerr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/lipence/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+type Event struct {
+	EventID     string                            `json:"event_id"`
+	Timestamp   time.Time                         `json:"timestamp"`
+	Platform    string                            `json:"platform"`
+	Level       string                            `json:"level"`
+	Message     string                            `json:"message,omitempty"`
+	Release     string                            `json:"release,omitempty"`
+	Environment string                            `json:"environment,omitempty"`
+	ServerName  string                            `json:"server_name,omitempty"`
+	Exception   Exceptions                        `json:"exception"`
+	Extra       map[string]interface{}            `json:"extra,omitempty"`
+	Contexts    map[string]map[string]interface{} `json:"contexts,omitempty"`
+	Tags        map[string]string                 `json:"tags,omitempty"`
+	Fingerprint []string                          `json:"fingerprint,omitempty"`
+}
+
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+var mainModulePath string
+
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		mainModulePath = info.Main.Path
+	}
+}
+
+// NewEvent converts err to an event, each layer of the error chain becoming
+// an entry of exception.values, from the innermost cause to the outermost.
+func NewEvent(err error) *Event {
+	var event = &Event{
+		EventID:   newEventID(),
+		Timestamp: time.Now().UTC(),
+		Platform:  "go",
+		Level:     "error",
+	}
+	if err == nil {
+		return event
+	}
+	var layers = errors.Layers(err)
+	var batchFingerprint []string
+	event.Exception.Values = make([]Exception, 0, len(layers))
+	for i, layer := range layers {
+		var exception = Exception{Type: layer.Code(), Value: layer.Message()}
+		if exception.Type == "" && layer.Err == nil {
+			exception.Type = "note"
+		} else if exception.Type == "" {
+			exception.Type = fmt.Sprintf("%T", layer.Err)
+		} else if _, isBatch := layer.Err.(errors.BatchErrors); isBatch {
+			// the code of a batch depends on its errors, it only identifies
+			// events having no other code
+			batchFingerprint = append(batchFingerprint, exception.Type)
+		} else {
+			event.Fingerprint = append(event.Fingerprint, exception.Type)
+		}
+		if frames := layer.Frames(); len(frames) > 0 {
+			exception.Stacktrace = &Stacktrace{Frames: convertFrames(frames)}
+			exception.Module = exception.Stacktrace.Frames[len(frames)-1].Module
+		}
+		event.Exception.Values = append(event.Exception.Values, exception)
+		if data := dataMap(layer.Data); len(data) > 0 {
+			if event.Extra == nil {
+				event.Extra = make(map[string]interface{})
+				event.Contexts = make(map[string]map[string]interface{})
+			}
+			for key, value := range data {
+				event.Extra[key] = value
+			}
+			event.Contexts[fmt.Sprintf("layer.%d", i)] = data
+		}
+	}
+	if len(event.Fingerprint) == 0 {
+		event.Fingerprint = batchFingerprint
+	}
+	if len(event.Fingerprint) == 0 {
+		event.Fingerprint = []string{"{{ default }}"}
+	}
+	if m, ok := err.(errors.Message); ok {
+		event.Message = m.Message()
+	}
+	return event
+}
+
+// convertFrames converts frames from the innermost call to the outermost one
+// into sentry frames, which are ordered from the outermost call.
+func convertFrames(frames []runtime.Frame) []Frame {
+	var converted = make([]Frame, len(frames))
+	for i, frame := range frames {
+		var module, function = splitFunctionName(frame.Function)
+		converted[len(frames)-1-i] = Frame{
+			Function: function,
+			Module:   module,
+			Filename: trimFilename(frame.File, module),
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    isInApp(module),
+		}
+	}
+	return converted
+}
+
+// splitFunctionName splits a fully qualified function name such as
+// `example.com/pkg.(*T).Method` into package path and function name.
+func splitFunctionName(name string) (module, function string) {
+	var slash = strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		return name[:slash+1+dot], name[slash+1+dot+1:]
+	}
+	return "", name
+}
+
+func trimFilename(file, module string) string {
+	if module == "" {
+		return file
+	}
+	if i := strings.Index(file, module+"/"); i >= 0 {
+		return file[i:]
+	}
+	return file
+}
+
+func isInApp(module string) bool {
+	if module == "main" {
+		return true
+	}
+	if mainModulePath == "" || module == "" {
+		return false
+	}
+	return module == mainModulePath || strings.HasPrefix(module, mainModulePath+"/")
+}
+
+func dataMap(data []errors.Field) map[string]interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	var encoder = zapcore.NewMapObjectEncoder()
+	for i := 0; i < len(data); i++ {
+		data[i].AddTo(encoder)
+	}
+	return encoder.Fields
+}
+
+func newEventID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return strings.Repeat("0", 32)
+	}
+	return hex.EncodeToString(id[:])
+}
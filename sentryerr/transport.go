@@ -0,0 +1,155 @@
+package sentryerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lipence/errors"
+)
+
+const (
+	sentryVersion = "7"
+	sentryClient  = "lipence-errors/0.1"
+)
+
+type DSN struct {
+	raw       string
+	scheme    string
+	publicKey string
+	host      string
+	path      string
+	projectID string
+}
+
+func ParseDSN(raw string) (*DSN, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, errors.NewSysErrf("invalid dsn: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.NewSysErrf("invalid dsn scheme: %q", u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.NewSysErr("invalid dsn: missing public key")
+	}
+	var path, projectID = "", strings.TrimPrefix(u.Path, "/")
+	if i := strings.LastIndexByte(projectID, '/'); i >= 0 {
+		path, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	if projectID == "" {
+		return nil, errors.NewSysErr("invalid dsn: missing project id")
+	}
+	return &DSN{
+		raw:       raw,
+		scheme:    u.Scheme,
+		publicKey: u.User.Username(),
+		host:      u.Host,
+		path:      path,
+		projectID: projectID,
+	}, nil
+}
+
+func (d *DSN) String() string {
+	return d.raw
+}
+
+func (d *DSN) EnvelopeURL() string {
+	return fmt.Sprintf("%s://%s%s/api/%s/envelope/", d.scheme, d.host, d.path, d.projectID)
+}
+
+func (d *DSN) AuthHeader() string {
+	return fmt.Sprintf("Sentry sentry_version=%s, sentry_client=%s, sentry_key=%s",
+		sentryVersion, sentryClient, d.publicKey)
+}
+
+// EncodeEnvelope writes event to w as an envelope holding a single event item.
+func EncodeEnvelope(w io.Writer, dsn *DSN, event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var header = struct {
+		EventID string    `json:"event_id"`
+		SentAt  time.Time `json:"sent_at"`
+		DSN     string    `json:"dsn,omitempty"`
+	}{EventID: event.EventID, SentAt: time.Now().UTC()}
+	if dsn != nil {
+		header.DSN = dsn.String()
+	}
+	var itemHeader = struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}{Type: "event", Length: len(payload)}
+	var encoder = json.NewEncoder(w)
+	if err = encoder.Encode(header); err != nil {
+		return err
+	}
+	if err = encoder.Encode(itemHeader); err != nil {
+		return err
+	}
+	if _, err = w.Write(payload); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte{'\n'})
+	return err
+}
+
+type Client struct {
+	DSN         *DSN
+	HTTPClient  *http.Client
+	Release     string
+	Environment string
+	ServerName  string
+}
+
+func NewClient(dsn string) (*Client, error) {
+	parsed, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{DSN: parsed, HTTPClient: http.DefaultClient}, nil
+}
+
+func (c *Client) Capture(err error) (eventID string, sendErr error) {
+	if err == nil {
+		return "", nil
+	}
+	var event = NewEvent(err)
+	event.Release = c.Release
+	event.Environment = c.Environment
+	event.ServerName = c.ServerName
+	return event.EventID, c.Send(event)
+}
+
+func (c *Client) Send(event *Event) error {
+	var body bytes.Buffer
+	if err := EncodeEnvelope(&body, c.DSN, event); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.DSN.EnvelopeURL(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", c.DSN.AuthHeader())
+	var httpClient = c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return errors.NewSysErrf("sentry responded with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package sqlerr
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	sysErr "errors"
+	"reflect"
+
+	"github.com/lipence/errors"
+)
+
+const TagRetryable = "retryable"
+
+const (
+	KeySQLState   = "sqlstate"
+	KeyConstraint = "constraint"
+	KeyTable      = "table"
+)
+
+var (
+	ErrSQL                  = errors.New("sql.error", "database error")
+	ErrNoRows               = ErrSQL.Define("sql.no_rows", "no rows in result set")
+	ErrTxDone               = ErrSQL.Define("sql.tx_done", "transaction has already been committed or rolled back")
+	ErrConnection           = ErrSQL.Define("sql.connection", "database connection failed")
+	ErrIntegrity            = ErrSQL.Define("sql.integrity", "integrity constraint violation")
+	ErrUniqueViolation      = ErrIntegrity.Define("sql.integrity.unique", "unique constraint violation")
+	ErrForeignKeyViolation  = ErrIntegrity.Define("sql.integrity.foreign_key", "foreign key constraint violation")
+	ErrNotNullViolation     = ErrIntegrity.Define("sql.integrity.not_null", "not null constraint violation")
+	ErrCheckViolation       = ErrIntegrity.Define("sql.integrity.check", "check constraint violation")
+	ErrRollback             = ErrSQL.Define("sql.rollback", "transaction rolled back")
+	ErrSerializationFailure = ErrRollback.Define("sql.rollback.serialization", "serialization failure", errors.WithTags(TagRetryable))
+	ErrDeadlock             = ErrRollback.Define("sql.rollback.deadlock", "deadlock detected", errors.WithTags(TagRetryable))
+)
+
+// StateError is implemented by the driver errors exposing a SQLSTATE, such as
+// the ones of pgx and lib/pq.
+type StateError interface {
+	error
+	SQLState() string
+}
+
+var states = map[string]errors.Reason{
+	"23505": ErrUniqueViolation,
+	"23503": ErrForeignKeyViolation,
+	"23502": ErrNotNullViolation,
+	"23514": ErrCheckViolation,
+	"40001": ErrSerializationFailure,
+	"40P01": ErrDeadlock,
+}
+
+// classes maps the two first characters of SQLSTATEs to definitions.
+var classes = map[string]errors.Reason{
+	"08": ErrConnection,
+	"23": ErrIntegrity,
+	"40": ErrRollback,
+}
+
+// Classify returns a traced error caused by err, whose reason is the
+// definition of its SQLSTATE or of the database/sql error it is, with the
+// SQLSTATE, constraint and table of the driver error as data. The classified
+// error is still err for Is. Errors which are already traced, have a code, or
+// are not mapped are returned unchanged, as by errors.Classify.
+func Classify(err error) error {
+	if _, ok := err.(errors.Message); ok {
+		return err
+	}
+	reason, fields, ok := classify(err)
+	if !ok {
+		return err
+	}
+	return errors.Build(reason).Cause(err).With(fields...).Classified().Skip(1).Err()
+}
+
+// Classifier maps the same errors as Classify, without their data, to be
+// registered with errors.OnClassify so that errors.From and errors.Classify
+// classify them.
+func Classifier(err error) (errors.Reason, bool) {
+	reason, _, ok := classify(err)
+	return reason, ok
+}
+
+func IsRetryable(err error) bool {
+	return errors.HasTag(err, TagRetryable)
+}
+
+func classify(err error) (reason errors.Reason, fields []errors.Field, ok bool) {
+	switch {
+	case err == nil:
+		return nil, nil, false
+	case sysErr.Is(err, sql.ErrNoRows):
+		return ErrNoRows, nil, true
+	case sysErr.Is(err, sql.ErrTxDone):
+		return ErrTxDone, nil, true
+	case sysErr.Is(err, driver.ErrBadConn), sysErr.Is(err, sql.ErrConnDone):
+		return ErrConnection, nil, true
+	}
+	var stateErr StateError
+	if !sysErr.As(err, &stateErr) {
+		return nil, nil, false
+	}
+	var state = stateErr.SQLState()
+	if reason, ok = states[state]; !ok {
+		if len(state) < 2 {
+			return nil, nil, false
+		}
+		if reason, ok = classes[state[:2]]; !ok {
+			reason = ErrSQL
+		}
+	}
+	fields = append(fields, errors.String(KeySQLState, state))
+	if constraint := stringField(stateErr, "ConstraintName", "Constraint"); constraint != "" {
+		fields = append(fields, errors.String(KeyConstraint, constraint))
+	}
+	if table := stringField(stateErr, "TableName", "Table"); table != "" {
+		fields = append(fields, errors.String(KeyTable, table))
+	}
+	return reason, fields, true
+}
+
+// stringField returns the first non-empty value of the string fields or
+// methods of err with one of names, as drivers expose them either way.
+func stringField(err error, names ...string) string {
+	var v = reflect.ValueOf(err)
+	for _, name := range names {
+		if m := v.MethodByName(name); m.IsValid() {
+			if fn, ok := m.Interface().(func() string); ok {
+				if s := fn(); s != "" {
+					return s
+				}
+			}
+		}
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+			return f.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package sqlerr_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/lipence/errors"
+	"github.com/lipence/errors/sqlerr"
+	"github.com/lipence/errors/sqlerr/sqlerrtest"
+)
+
+// methodError exposes its constraint and table by methods, like pgx errors.
+type methodError struct {
+	state string
+	Table string
+}
+
+func (e *methodError) Error() string      { return "driver error" }
+func (e *methodError) SQLState() string   { return e.state }
+func (e *methodError) Constraint() string { return "fk_orders_user" }
+
+func assertData(t *testing.T, err error, key, want string) {
+	t.Helper()
+	if got, _ := errors.DataString(err, key); got != want {
+		t.Errorf("data %s = %q, want %q", key, got, want)
+	}
+}
+
+func TestNoRows(t *testing.T) {
+	db, _ := sqlerrtest.Open()
+	defer db.Close()
+	var v string
+	var err = sqlerr.Classify(db.QueryRow("SELECT value").Scan(&v))
+	if !errors.Is(err, sqlerr.ErrNoRows) || !errors.Is(err, sqlerr.ErrSQL) {
+		t.Errorf("no rows is not ErrNoRows: %v", err)
+	}
+	if !errors.CausedBy(err, sql.ErrNoRows, true) {
+		t.Errorf("no rows is not caused by sql.ErrNoRows: %v", err)
+	}
+}
+
+func TestTxDone(t *testing.T) {
+	db, _ := sqlerrtest.Open()
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = sqlerr.Classify(tx.Rollback()); !errors.Is(err, sqlerr.ErrTxDone) {
+		t.Errorf("rollback after commit is not ErrTxDone: %v", err)
+	}
+}
+
+func TestBadConn(t *testing.T) {
+	db, d := sqlerrtest.Open()
+	defer db.Close()
+	d.Fail(driver.ErrBadConn)
+	for i := 0; i < 2; i++ {
+		_, err := db.Exec("UPDATE users")
+		if err = sqlerr.Classify(err); !errors.Is(err, sqlerr.ErrConnection) {
+			t.Errorf("exec %d with a bad connection is not ErrConnection: %v", i, err)
+		}
+		if sqlerr.IsRetryable(err) {
+			t.Errorf("bad connection is retryable")
+		}
+	}
+	d.Clear()
+	if _, err := db.Exec("UPDATE users"); err != nil {
+		t.Errorf("exec after Clear: %v", err)
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	db, d := sqlerrtest.Open()
+	defer db.Close()
+	d.Fail(&sqlerrtest.StateError{State: "23505", ConstraintName: "users_email_key", TableName: "users", Message: "duplicate key"})
+	_, err := db.Exec("INSERT INTO users")
+	err = sqlerr.Classify(err)
+	if !errors.Is(err, sqlerr.ErrUniqueViolation) || !errors.Is(err, sqlerr.ErrIntegrity) {
+		t.Errorf("23505 is not ErrUniqueViolation: %v", err)
+	}
+	if errors.Is(err, sqlerr.ErrForeignKeyViolation) {
+		t.Errorf("23505 is ErrForeignKeyViolation")
+	}
+	assertData(t, err, sqlerr.KeySQLState, "23505")
+	assertData(t, err, sqlerr.KeyConstraint, "users_email_key")
+	assertData(t, err, sqlerr.KeyTable, "users")
+	if _, err = db.Exec("INSERT INTO users"); err != nil {
+		t.Errorf("second exec: %v", err)
+	}
+}
+
+func TestForeignKeyViolation(t *testing.T) {
+	var err = sqlerr.Classify(fmt.Errorf("insert: %w", &methodError{state: "23503", Table: "orders"}))
+	if !errors.Is(err, sqlerr.ErrForeignKeyViolation) || !errors.Is(err, sqlerr.ErrIntegrity) {
+		t.Errorf("23503 is not ErrForeignKeyViolation: %v", err)
+	}
+	assertData(t, err, sqlerr.KeySQLState, "23503")
+	assertData(t, err, sqlerr.KeyConstraint, "fk_orders_user")
+	assertData(t, err, sqlerr.KeyTable, "orders")
+}
+
+func TestStateClasses(t *testing.T) {
+	var tests = []struct {
+		state     string
+		reason    error
+		retryable bool
+	}{
+		{"23502", sqlerr.ErrNotNullViolation, false},
+		{"23999", sqlerr.ErrIntegrity, false},
+		{"40001", sqlerr.ErrSerializationFailure, true},
+		{"40P01", sqlerr.ErrDeadlock, true},
+		{"40002", sqlerr.ErrRollback, false},
+		{"08006", sqlerr.ErrConnection, false},
+		{"42601", sqlerr.ErrSQL, false},
+	}
+	for _, test := range tests {
+		var err = sqlerr.Classify(&sqlerrtest.StateError{State: test.state})
+		if !errors.Is(err, test.reason) {
+			t.Errorf("%s is not %v: %v", test.state, test.reason, err)
+		}
+		if got := sqlerr.IsRetryable(err); got != test.retryable {
+			t.Errorf("IsRetryable(%s) = %v, want %v", test.state, got, test.retryable)
+		}
+	}
+	if !sqlerr.IsRetryable(errors.Note(sqlerr.Classify(&sqlerrtest.StateError{State: "40001"}))) {
+		t.Errorf("noted serialization failure is not retryable")
+	}
+}
+
+func TestUnclassified(t *testing.T) {
+	var plain = fmt.Errorf("plain")
+	if err := sqlerr.Classify(plain); err != plain {
+		t.Errorf("Classify changed a non-SQL error: %v", err)
+	}
+	if err := sqlerr.Classify(nil); err != nil {
+		t.Errorf("Classify(nil) = %v", err)
+	}
+}
+
+func TestClassifier(t *testing.T) {
+	var r = errors.NewRegistry()
+	r.OnClassify(sqlerr.Classifier)
+	var err = r.Classify(&sqlerrtest.StateError{State: "23505", ConstraintName: "users_email_key"})
+	if !errors.Is(err, sqlerr.ErrUniqueViolation) {
+		t.Errorf("registered classifier does not map 23505 to ErrUniqueViolation: %v", err)
+	}
+}
+
+func TestClassifyIsCause(t *testing.T) {
+	var err = sqlerr.Classify(sql.ErrNoRows)
+	if !errors.Is(err, sqlerr.ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("classified no rows is not both ErrNoRows and sql.ErrNoRows: %v", err)
+	}
+	if errors.Is(err, sql.ErrTxDone) {
+		t.Errorf("classified no rows is sql.ErrTxDone")
+	}
+}
+
+var errLookup = errors.New("SQLTEST0001", "lookup failed")
+
+func TestClassifyCoded(t *testing.T) {
+	for _, err := range []error{
+		errors.Because(errLookup, sql.ErrNoRows),
+		errors.Note(sql.ErrNoRows),
+		sqlerr.ErrNoRows,
+	} {
+		if got := sqlerr.Classify(err); got != err {
+			t.Errorf("Classify(%v) = %v, want it unchanged", err, got)
+		}
+	}
+	var classified = sqlerr.Classify(sql.ErrNoRows)
+	if got := sqlerr.Classify(classified); got != classified {
+		t.Errorf("classified error classified again: %v", got)
+	}
+}
@@ -0,0 +1,151 @@
+// Package sqlerrtest provides a fake database/sql driver failing with chosen
+// errors, to test the handling of database failures without a database.
+package sqlerrtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// StateError is a driver error exposing a SQLSTATE, a constraint and a table
+// the way actual drivers do.
+type StateError struct {
+	State          string
+	ConstraintName string
+	TableName      string
+	Message        string
+}
+
+func (e *StateError) Error() string {
+	return e.Message + " (SQLSTATE " + e.State + ")"
+}
+
+func (e *StateError) SQLState() string {
+	return e.State
+}
+
+// Driver fails the statements executed or queried through it with the errors
+// queued by Fail, in order. Statements succeed without rows otherwise.
+//
+// database/sql retries the statements failing with driver.ErrBadConn on other
+// connections, so errors matching it are sticky: they fail every statement
+// until Clear is called.
+type Driver struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+// Open returns a database backed by a new fake driver.
+func Open() (*sql.DB, *Driver) {
+	var d = &Driver{}
+	return sql.OpenDB(d), d
+}
+
+// Fail queues errs to be returned by the next statements.
+func (d *Driver) Fail(errs ...error) {
+	d.mu.Lock()
+	d.errs = append(d.errs, errs...)
+	d.mu.Unlock()
+}
+
+// Clear drops the queued errors, sticky ones included.
+func (d *Driver) Clear() {
+	d.mu.Lock()
+	d.errs = nil
+	d.mu.Unlock()
+}
+
+func (d *Driver) next() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.errs) == 0 {
+		return nil
+	}
+	var err = d.errs[0]
+	if !errors.Is(err, driver.ErrBadConn) {
+		d.errs = d.errs[1:]
+	}
+	return err
+}
+
+func (d *Driver) Connect(context.Context) (driver.Conn, error) {
+	return &conn{d}, nil
+}
+
+func (d *Driver) Driver() driver.Driver {
+	return d
+}
+
+func (d *Driver) Open(string) (driver.Conn, error) {
+	return &conn{d}, nil
+}
+
+type conn struct {
+	d *Driver
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return &stmt{c.d}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return tx{}, nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error {
+	return nil
+}
+
+func (tx) Rollback() error {
+	return nil
+}
+
+type stmt struct {
+	d *Driver
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.d.next(); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := s.d.next(); err != nil {
+		return nil, err
+	}
+	return rows{}, nil
+}
+
+type rows struct{}
+
+func (rows) Columns() []string {
+	return []string{"value"}
+}
+
+func (rows) Close() error {
+	return nil
+}
+
+func (rows) Next([]driver.Value) error {
+	return io.EOF
+}
@@ -3,6 +3,10 @@ package errors
 import (
 	"fmt"
 	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type Tracer interface {
@@ -17,10 +21,45 @@ func (t *tracer) Stack() []uintptr {
 	return t.stack
 }
 
-func (t *tracer) trace(skip int) {
-	pcs := make([]uintptr, maxStackDepth)
+func (t *tracer) trace(skip, depth int) {
+	pcs := make([]uintptr, depth)
 	n := runtime.Callers(skip+2, pcs)
-	t.stack = pcs[:n]
+	t.stack = trimHelpers(pcs[:n])
+}
+
+var helpers sync.Map // function name => struct{}
+var hasHelpers int32
+
+// Helper marks the calling function as an error helper, like testing.T.Helper.
+// Helper functions are omitted from the stack traces of errors created inside
+// them, so that traces start at the caller of the helper.
+func Helper() {
+	var pc [1]uintptr
+	if runtime.Callers(2, pc[:]) == 0 {
+		return
+	}
+	if fn := runtime.FuncForPC(pc[0] - 1); fn != nil {
+		if _, ok := helpers.Load(fn.Name()); !ok {
+			helpers.Store(fn.Name(), struct{}{})
+			atomic.StoreInt32(&hasHelpers, 1)
+		}
+	}
+}
+
+func trimHelpers(stack []uintptr) []uintptr {
+	if atomic.LoadInt32(&hasHelpers) == 0 {
+		return stack
+	}
+	var trimmed = stack[:0]
+	for _, pc := range stack {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil {
+			if _, ok := helpers.Load(fn.Name()); ok {
+				continue
+			}
+		}
+		trimmed = append(trimmed, pc)
+	}
+	return trimmed
 }
 
 type traceInfoItem struct {
@@ -61,3 +100,23 @@ func (t *tracer) InfoStack(parent Tracer) []traceInfoItem {
 	}
 	return infoStack
 }
+
+var mainPackagePath = func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Path
+	}
+	return ""
+}()
+
+// funcPackage returns the import path of the package of a function given by
+// its full name, such as `example.com/pkg.(*T).Method`.
+func funcPackage(name string) string {
+	var slash = strings.LastIndexByte(name, '/')
+	if dot := strings.IndexByte(name[slash+1:], '.'); dot >= 0 {
+		name = name[:slash+1+dot]
+	}
+	if name == "main" && mainPackagePath != "" {
+		return mainPackagePath
+	}
+	return name
+}
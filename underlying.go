@@ -1,13 +1,49 @@
 package errors
 
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
 type Message interface {
 	Code() string
 	Message() string
 }
 
+// Reason is the definition of an error layer, as created by New. Any error
+// carrying a code and a message can be used as the reason of Because.
+type Reason interface {
+	Message
+	error
+}
+
+// isNilReason reports whether reason is nil or holds a nil pointer, map, slice,
+// func or channel, whose code could not be read.
+func isNilReason(reason Reason) bool {
+	if reason == nil {
+		return true
+	}
+	if u, ok := reason.(*underlying); ok {
+		return u == nil
+	}
+	switch v := reflect.ValueOf(reason); v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
 type underlying struct {
 	code    string
 	message string
+	parent  *underlying
+	tags    []string
+	meta    map[string]string
+	reg     *Registry
+
+	deprecation *deprecation
+	aliases     atomic.Value // []alias, see addAlias
 }
 
 func (e *underlying) Code() string {
@@ -18,17 +54,97 @@ func (e *underlying) Message() string {
 	return e.message
 }
 
+// Is reports whether e is target, an alias of target, a descendant definition
+// of either, or a definition whose dotted code is in the namespace of
+// target's code, e.g. `payment.card.declined` is `payment.card`.
 func (e *underlying) Is(target error) bool {
-	if u, ok := target.(*underlying); ok {
-		if e.code != "" {
-			return e.code == u.code
+	switch t := target.(type) {
+	case *underlying:
+		for d := e; d != nil; d = d.parent {
+			if d.equal(t) || d.aliasOf(t) {
+				return true
+			}
+		}
+		return t.code != "" && inCategory(e.code, t.code)
+	case Category:
+		return e.InCategory(string(t))
+	}
+	return Is(target, e)
+}
+
+func (e *underlying) equal(u *underlying) bool {
+	if e == u {
+		return true
+	}
+	if e.code != "" {
+		return e.code == u.code
+	}
+	if e.message != "" {
+		return e.message == u.message
+	}
+	return false
+}
+
+// InCategory reports whether the code of e or of any of its parent
+// definitions is category or in its dotted namespace.
+func (e *underlying) InCategory(category string) bool {
+	for d := e; d != nil; d = d.parent {
+		if inCategory(d.code, category) {
+			return true
 		}
-		if e.message != "" {
-			return e.message == u.message
+	}
+	return false
+}
+
+func (e *underlying) Parent() *underlying {
+	return e.parent
+}
+
+// Define creates a child definition of e, which is e as well, and inherits
+// its tags and metadata.
+func (e *underlying) Define(code, msg string, opts ...DefOption) *underlying {
+	code, msg = registryOf(e.reg).filter(code, msg)
+	var def = &underlying{code: code, message: msg, parent: e, reg: e.reg}
+	for _, opt := range opts {
+		opt(def)
+	}
+	registerDefinition(def, 1)
+	return def
+}
+
+func inCategory(code, category string) bool {
+	return code != "" && category != "" &&
+		(code == category || strings.HasPrefix(code, category+"."))
+}
+
+// Category matches errors whose definition code is in a dotted namespace,
+// such as `payment.card`, when used as the target of Is or CausedBy.
+type Category string
+
+func (c Category) Error() string {
+	return "category " + string(c)
+}
+
+func (c Category) match(err error) bool {
+	switch e := err.(type) {
+	case *node:
+		if u := e.Underlying(); u != nil {
+			return c.match(u)
 		}
 		return false
+	case *underlying:
+		return e.InCategory(string(c))
+	case BatchErrors:
+		for _, err := range e {
+			if c.match(err) {
+				return true
+			}
+		}
+		return inCategory(e.Code(), string(c))
+	case Message:
+		return inCategory(e.Code(), string(c))
 	}
-	return Is(target, e)
+	return false
 }
 
 func (e *underlying) Error() string {
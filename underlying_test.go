@@ -0,0 +1,38 @@
+package errors_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/lipence/errors"
+)
+
+type ptrReason struct{ code string }
+
+func (r *ptrReason) Error() string   { return r.code }
+func (r *ptrReason) Code() string    { return r.code }
+func (r *ptrReason) Message() string { return r.code }
+
+type mapReason map[string]string
+
+func (r mapReason) Error() string   { return r["code"] }
+func (r mapReason) Code() string    { return r["code"] }
+func (r mapReason) Message() string { return r["code"] }
+
+func TestTypedNilReason(t *testing.T) {
+	for _, reason := range []errors.Reason{(*ptrReason)(nil), mapReason(nil)} {
+		var err = errors.Because(reason, io.EOF)
+		if got := code(err); got != "" {
+			t.Errorf("%T: code = %q, want none", reason, got)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("%T: error is not its cause", reason)
+		}
+		if err = errors.Build(reason).Err(); err != nil {
+			t.Errorf("%T: built error = %v, want nil", reason, err)
+		}
+	}
+	if got := code(errors.Because(&ptrReason{"PTR0001"}, io.EOF)); got != "PTR0001" {
+		t.Errorf("code = %q, want PTR0001", got)
+	}
+}